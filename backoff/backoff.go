@@ -0,0 +1,30 @@
+// Package backoff computes retry delays shared by the packages that retry
+// connections or messages.
+package backoff
+
+import "time"
+
+// DefaultMax caps Exponential when no maximum is given.
+const DefaultMax = 30 * time.Second
+
+// Exponential returns the wait before retry n (starting at 1): base doubled
+// n-1 times, capped at max. A max of zero or less uses DefaultMax, and a
+// base of zero or less means no wait.
+func Exponential(base, max time.Duration, n int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	if max <= 0 {
+		max = DefaultMax
+	}
+
+	delay := base
+	for i := 1; i < n; i++ {
+		// Compare before doubling so large n cannot overflow.
+		if delay >= max/2 {
+			return max
+		}
+		delay *= 2
+	}
+	return min(delay, max)
+}
@@ -0,0 +1,34 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExponential(t *testing.T) {
+	tests := []struct {
+		name string
+		base time.Duration
+		max  time.Duration
+		n    int
+		want time.Duration
+	}{
+		{"first retry", time.Second, time.Minute, 1, time.Second},
+		{"doubles", time.Second, time.Minute, 4, 8 * time.Second},
+		{"capped", time.Second, time.Minute, 7, time.Minute},
+		{"base above max", 2 * time.Minute, time.Minute, 1, time.Minute},
+		{"default max", time.Second, 0, 10, DefaultMax},
+		{"large n does not overflow", time.Second, time.Hour, 35, time.Hour},
+		{"huge n", time.Nanosecond, time.Duration(1<<63 - 1), 1000, time.Duration(1<<63 - 1)},
+		{"zero n", time.Second, time.Minute, 0, time.Second},
+		{"zero base", 0, time.Minute, 5, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Exponential(tt.base, tt.max, tt.n); got != tt.want {
+				t.Errorf("Exponential(%s, %s, %d) = %s, want %s", tt.base, tt.max, tt.n, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,22 +1,122 @@
 package database
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/11SF/go-common/postgres"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
 )
 
+// DefaultConnectTimeout bounds InitDatabase so an unreachable host fails
+// instead of blocking on TCP retries.
+const DefaultConnectTimeout = 10 * time.Second
+
 type Config struct {
 	Dial       gorm.Dialector
 	GormConfig gorm.Config
+	Pool       PoolConfig
+	// PingTimeout bounds the connection check on top of the caller's ctx.
+	// Zero relies on ctx alone.
+	PingTimeout time.Duration
+
+	// EnableQueryLogging logs every query through postgres.NewGormLogger.
+	// SlowQueryThreshold alone installs the same logger at Warn level, so
+	// only errors and slow queries are logged. Either one replaces
+	// GormConfig.Logger.
+	EnableQueryLogging bool
+	SlowQueryThreshold time.Duration
+	// QueryLogger receives the query records. Nil uses the logger package
+	// default.
+	QueryLogger *zap.Logger
+}
+
+// PoolConfig tunes the underlying sql.DB pool. Zero values leave the
+// database/sql defaults, which include no limit on open connections.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+func (pc PoolConfig) apply(sqlDB *sql.DB) {
+	if pc.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(pc.MaxOpenConns)
+	}
+	if pc.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(pc.MaxIdleConns)
+	}
+	if pc.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(pc.ConnMaxLifetime)
+	}
+	if pc.ConnMaxIdleTime > 0 {
+		sqlDB.SetConnMaxIdleTime(pc.ConnMaxIdleTime)
+	}
 }
 
+// InitDatabase is InitDatabaseCtx with a DefaultConnectTimeout deadline.
 func InitDatabase(cf *Config) (*gorm.DB, error) {
-	db, err := gorm.Open(cf.Dial, &cf.GormConfig)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultConnectTimeout)
+	defer cancel()
+	return InitDatabaseCtx(ctx, cf)
+}
+
+// InitDatabaseCtx opens the database, applies cf.Pool and pings it within
+// ctx and cf.PingTimeout, returning ctx.Err() if ctx is cancelled or expires
+// first.
+func InitDatabaseCtx(ctx context.Context, cf *Config) (*gorm.DB, error) {
+	// gorm's automatic ping ignores ctx; the connection is checked below.
+	gormConfig := cf.GormConfig
+	gormConfig.DisableAutomaticPing = true
+	// The logger is set on this copy, so repeated calls never stack it.
+	if gormLog := cf.queryLogger(); gormLog != nil {
+		gormConfig.Logger = gormLog
+	}
+
+	db, err := gorm.Open(cf.Dial, &gormConfig)
 	if err != nil {
 		return nil, err
 	}
-	err = db.Exec("SELECT 1").Error
+	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, err
 	}
+	cf.Pool.apply(sqlDB)
+
+	pingCtx := ctx
+	if cf.PingTimeout > 0 {
+		var cancel context.CancelFunc
+		pingCtx, cancel = context.WithTimeout(ctx, cf.PingTimeout)
+		defer cancel()
+	}
+	if err := sqlDB.PingContext(pingCtx); err != nil {
+		sqlDB.Close()
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("database: ping failed: %w", err)
+	}
 	return db, nil
 }
+
+func (cf *Config) queryLogger() gormlogger.Interface {
+	if !cf.EnableQueryLogging && cf.SlowQueryThreshold <= 0 {
+		return nil
+	}
+
+	level := gormlogger.Warn
+	if cf.EnableQueryLogging {
+		level = gormlogger.Info
+	}
+	return postgres.NewGormLogger(postgres.GormLoggerOptions{
+		Logger:                    cf.QueryLogger,
+		SlowThreshold:             cf.SlowQueryThreshold,
+		IgnoreRecordNotFoundError: true,
+		LogLevel:                  level,
+	})
+}
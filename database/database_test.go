@@ -0,0 +1,262 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/11SF/go-common/postgres"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// newSilentListener accepts TCP connections and never answers, like a
+// database host that completes the handshake and then hangs.
+func newSilentListener(t *testing.T) *net.TCPAddr {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr)
+}
+
+func postgresConfig(t *testing.T, host string, port int) *Config {
+	t.Helper()
+	dial, err := postgres.ConnectPostgres(&postgres.Config{
+		Host:     host,
+		Port:     port,
+		Username: "app",
+		Password: "secret",
+		DBName:   "orders",
+		SSLMode:  "disable",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Config{Dial: dial, GormConfig: quiet}
+}
+
+func TestInitDatabaseCtxUnreachable(t *testing.T) {
+	silent := newSilentListener(t)
+
+	tests := []struct {
+		name string
+		host string
+		port int
+	}{
+		{"silent server", silent.IP.String(), silent.Port},
+		{"non-routable address", "10.255.255.1", 5432},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const timeout = 500 * time.Millisecond
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			start := time.Now()
+			db, err := InitDatabaseCtx(ctx, postgresConfig(t, tt.host, tt.port))
+			elapsed := time.Since(start)
+			if err == nil {
+				t.Fatalf("InitDatabaseCtx() = %v, want an error", db)
+			}
+			if elapsed > timeout+time.Second {
+				t.Errorf("InitDatabaseCtx() returned after %s, want within %s", elapsed, timeout)
+			}
+		})
+	}
+}
+
+func TestInitDatabaseCtxDeadline(t *testing.T) {
+	silent := newSilentListener(t)
+
+	t.Run("ctx deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		_, err := InitDatabaseCtx(ctx, postgresConfig(t, silent.IP.String(), silent.Port))
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("InitDatabaseCtx() error = %v, want context.DeadlineExceeded", err)
+		}
+	})
+
+	t.Run("cancelled ctx", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := InitDatabaseCtx(ctx, postgresConfig(t, silent.IP.String(), silent.Port))
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("InitDatabaseCtx() error = %v, want context.Canceled", err)
+		}
+	})
+
+	t.Run("ping timeout", func(t *testing.T) {
+		cf := postgresConfig(t, silent.IP.String(), silent.Port)
+		cf.PingTimeout = 200 * time.Millisecond
+
+		start := time.Now()
+		_, err := InitDatabaseCtx(context.Background(), cf)
+		if err == nil || !strings.HasPrefix(err.Error(), "database: ping failed: ") {
+			t.Fatalf("InitDatabaseCtx() error = %v, want a wrapped ping failure", err)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("InitDatabaseCtx() returned after %s, want within the 200ms PingTimeout", elapsed)
+		}
+	})
+}
+
+func TestInitDatabaseCtxPool(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("applied", func(t *testing.T) {
+		db, err := InitDatabaseCtx(ctx, &Config{
+			Dial:       newSQLite(t, "pool.db"),
+			GormConfig: quiet,
+			Pool: PoolConfig{
+				MaxOpenConns:    3,
+				MaxIdleConns:    2,
+				ConnMaxLifetime: time.Hour,
+				ConnMaxIdleTime: time.Minute,
+			},
+		})
+		if err != nil {
+			t.Fatalf("InitDatabaseCtx() error = %v", err)
+		}
+		sqlDB, _ := db.DB()
+		defer sqlDB.Close()
+
+		if got := sqlDB.Stats().MaxOpenConnections; got != 3 {
+			t.Errorf("MaxOpenConnections = %d, want 3", got)
+		}
+
+		// Hold every allowed connection, then return them: only two stay idle.
+		var conns []*sql.Conn
+		for i := 0; i < 3; i++ {
+			conn, err := sqlDB.Conn(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			conns = append(conns, conn)
+		}
+		waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		if conn, err := sqlDB.Conn(waitCtx); err == nil {
+			conn.Close()
+			t.Error("got a fourth connection past MaxOpenConns")
+		}
+		for _, conn := range conns {
+			conn.Close()
+		}
+		if stats := sqlDB.Stats(); stats.Idle != 2 || stats.MaxIdleClosed != 1 {
+			t.Errorf("Idle = %d, MaxIdleClosed = %d, want 2 and 1", stats.Idle, stats.MaxIdleClosed)
+		}
+	})
+
+	t.Run("zero leaves defaults", func(t *testing.T) {
+		db, err := InitDatabaseCtx(ctx, &Config{Dial: newSQLite(t, "default.db"), GormConfig: quiet})
+		if err != nil {
+			t.Fatalf("InitDatabaseCtx() error = %v", err)
+		}
+		sqlDB, _ := db.DB()
+		defer sqlDB.Close()
+		if got := sqlDB.Stats().MaxOpenConnections; got != 0 {
+			t.Errorf("MaxOpenConnections = %d, want 0 (unlimited)", got)
+		}
+	})
+}
+
+func TestInitDatabaseCtxQueryLogger(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		enable      bool
+		slow        time.Duration
+		wantQueries int
+		wantSlow    int
+	}{
+		{"unset", false, 0, 0, 0},
+		{"query logging", true, 0, 1, 0},
+		{"slow queries only", false, time.Nanosecond, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+			cf := &Config{
+				Dial:               newSQLite(t, "log.db"),
+				GormConfig:         quiet,
+				EnableQueryLogging: tt.enable,
+				SlowQueryThreshold: tt.slow,
+				QueryLogger:        zap.New(core),
+			}
+			db, err := InitDatabaseCtx(ctx, cf)
+			if err != nil {
+				t.Fatalf("InitDatabaseCtx() error = %v", err)
+			}
+			sqlDB, _ := db.DB()
+			defer sqlDB.Close()
+
+			if err := db.Exec("SELECT 1").Error; err != nil {
+				t.Fatal(err)
+			}
+			if n := logs.FilterMessage("gorm query").Len(); n != tt.wantQueries {
+				t.Errorf("logged %d queries, want %d", n, tt.wantQueries)
+			}
+			if n := logs.FilterMessage("gorm slow query").Len(); n != tt.wantSlow {
+				t.Errorf("logged %d slow queries, want %d", n, tt.wantSlow)
+			}
+			installed := db.Config.Logger != gormlogger.Discard
+			if want := tt.enable || tt.slow > 0; installed != want {
+				t.Errorf("query logger installed = %v, want %v", installed, want)
+			}
+		})
+	}
+}
+
+func TestInitDatabaseCtxQueryLoggerNotStacked(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	cf := &Config{
+		Dial:               newSQLite(t, "log.db"),
+		GormConfig:         quiet,
+		EnableQueryLogging: true,
+		QueryLogger:        zap.New(core),
+	}
+
+	var db *gorm.DB
+	for i := 0; i < 3; i++ {
+		var err error
+		db, err = InitDatabaseCtx(context.Background(), cf)
+		if err != nil {
+			t.Fatalf("InitDatabaseCtx() call %d error = %v", i+1, err)
+		}
+		sqlDB, _ := db.DB()
+		defer sqlDB.Close()
+	}
+	if cf.GormConfig.Logger != gormlogger.Discard {
+		t.Error("InitDatabaseCtx() replaced cf.GormConfig.Logger")
+	}
+
+	if err := db.Exec("SELECT 1").Error; err != nil {
+		t.Fatal(err)
+	}
+	if n := logs.FilterMessage("gorm query").Len(); n != 1 {
+		t.Errorf("one query logged %d times after three Init calls, want 1", n)
+	}
+}
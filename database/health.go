@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/11SF/go-common/response"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Pinger is anything whose connection can be checked. *sql.DB implements it.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+type gormPinger struct {
+	db *gorm.DB
+}
+
+// WrapGorm adapts db to Pinger by pinging its underlying sql.DB.
+func WrapGorm(db *gorm.DB) Pinger {
+	return gormPinger{db: db}
+}
+
+func (gp gormPinger) PingContext(ctx context.Context) error {
+	if gp.db == nil {
+		return errors.New("database: nil database")
+	}
+	sqlDB, err := gp.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+type HealthResult struct {
+	OK      bool          `json:"ok"`
+	Latency time.Duration `json:"latency"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// CheckAll pings every pinger concurrently, each bounded by timeout, and
+// returns the results by name.
+func CheckAll(ctx context.Context, timeout time.Duration, pingers map[string]Pinger) map[string]HealthResult {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]HealthResult, len(pingers))
+	)
+	for name, pinger := range pingers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := check(ctx, timeout, pinger)
+			mu.Lock()
+			results[name] = result
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func check(ctx context.Context, timeout time.Duration, pinger Pinger) HealthResult {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := pinger.PingContext(ctx)
+	result := HealthResult{OK: err == nil, Latency: time.Since(start)}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// GinHealthHandler responds 200 with the CheckAll results, or 503 when any
+// pinger not listed in optional fails. Failures of optional pingers are
+// reported but do not change the status.
+func GinHealthHandler(timeout time.Duration, pingers map[string]Pinger, optional ...string) gin.HandlerFunc {
+	skip := make(map[string]struct{}, len(optional))
+	for _, name := range optional {
+		skip[name] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		results := CheckAll(c.Request.Context(), timeout, pingers)
+
+		var failed []string
+		for name, result := range results {
+			if _, ok := skip[name]; !ok && !result.OK {
+				failed = append(failed, name)
+			}
+		}
+		if len(failed) > 0 {
+			sort.Strings(failed)
+			msg := fmt.Sprintf("unhealthy: %s", strings.Join(failed, ", "))
+			response.NewGinResponseWithCode(c, http.StatusServiceUnavailable, response.GenericError, msg, results)
+			return
+		}
+		response.NewGinResponse(c, http.StatusOK, results)
+	}
+}
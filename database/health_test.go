@@ -0,0 +1,189 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// pingerFunc adapts a function to Pinger.
+type pingerFunc func(ctx context.Context) error
+
+func (f pingerFunc) PingContext(ctx context.Context) error {
+	return f(ctx)
+}
+
+// hang blocks until ctx is done.
+var hang = pingerFunc(func(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+})
+
+func openHealthDB(t *testing.T, name string) *gorm.DB {
+	t.Helper()
+	db, err := InitDatabaseCtx(context.Background(), &Config{Dial: newSQLite(t, name), GormConfig: quiet})
+	if err != nil {
+		t.Fatalf("InitDatabaseCtx() error = %v", err)
+	}
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func closedHealthDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := openHealthDB(t, "closed.db")
+	sqlDB, _ := db.DB()
+	if err := sqlDB.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestCheckAll(t *testing.T) {
+	pingers := map[string]Pinger{
+		"primary": WrapGorm(openHealthDB(t, "primary.db")),
+		"replica": WrapGorm(closedHealthDB(t)),
+		"nil":     WrapGorm(nil),
+		"slow":    hang,
+		"slower":  hang,
+		"slowest": hang,
+	}
+
+	const timeout = 100 * time.Millisecond
+	start := time.Now()
+	results := CheckAll(context.Background(), timeout, pingers)
+	elapsed := time.Since(start)
+
+	if len(results) != len(pingers) {
+		t.Fatalf("got %d results, want %d", len(results), len(pingers))
+	}
+	if primary := results["primary"]; !primary.OK || primary.Error != "" || primary.Latency <= 0 {
+		t.Errorf("primary = %+v, want ok with a latency", primary)
+	}
+	if replica := results["replica"]; replica.OK || !strings.Contains(replica.Error, "database is closed") {
+		t.Errorf("replica = %+v, want the closed pool error", replica)
+	}
+	if result := results["nil"]; result.OK || result.Error != "database: nil database" {
+		t.Errorf("nil = %+v, want database: nil database", result)
+	}
+	for _, name := range []string{"slow", "slower", "slowest"} {
+		if result := results[name]; result.OK || result.Error != context.DeadlineExceeded.Error() {
+			t.Errorf("%s = %+v, want a per-pinger timeout", name, result)
+		}
+	}
+	// The hanging pingers run concurrently, so the total is one timeout.
+	if elapsed > 2*timeout {
+		t.Errorf("CheckAll() took %v, want about one %v timeout", elapsed, timeout)
+	}
+}
+
+func TestCheckAllParentCtx(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results := CheckAll(ctx, time.Second, map[string]Pinger{"slow": hang})
+	if result := results["slow"]; result.OK || result.Error != context.Canceled.Error() {
+		t.Errorf("slow = %+v, want the parent ctx error", result)
+	}
+	if results := CheckAll(context.Background(), time.Second, nil); len(results) != 0 {
+		t.Errorf("CheckAll(nil) = %v, want no results", results)
+	}
+}
+
+type healthBody struct {
+	Code    string                  `json:"code"`
+	Message string                  `json:"message"`
+	Data    map[string]HealthResult `json:"data"`
+}
+
+func serveHealth(t *testing.T, h gin.HandlerFunc) (int, healthBody) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/health", h)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	var body healthBody
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body, err)
+	}
+	return rec.Code, body
+}
+
+func TestGinHealthHandler(t *testing.T) {
+	healthy := WrapGorm(openHealthDB(t, "healthy.db"))
+	closed := WrapGorm(closedHealthDB(t))
+	failing := pingerFunc(func(context.Context) error { return errors.New("connection refused") })
+
+	tests := []struct {
+		name        string
+		pingers     map[string]Pinger
+		optional    []string
+		wantStatus  int
+		wantCode    string
+		wantMessage string
+	}{
+		{
+			name:       "all healthy",
+			pingers:    map[string]Pinger{"primary": healthy},
+			wantStatus: http.StatusOK,
+			wantCode:   "00000",
+		},
+		{
+			name:        "critical failure",
+			pingers:     map[string]Pinger{"primary": healthy, "replica": closed},
+			wantStatus:  http.StatusServiceUnavailable,
+			wantCode:    "E9999",
+			wantMessage: "unhealthy: replica",
+		},
+		{
+			name:        "failures listed in order",
+			pingers:     map[string]Pinger{"primary": healthy, "replica": closed, "cache": failing},
+			wantStatus:  http.StatusServiceUnavailable,
+			wantCode:    "E9999",
+			wantMessage: "unhealthy: cache, replica",
+		},
+		{
+			name:       "optional failure",
+			pingers:    map[string]Pinger{"primary": healthy, "replica": closed},
+			optional:   []string{"replica"},
+			wantStatus: http.StatusOK,
+			wantCode:   "00000",
+		},
+		{
+			name:        "optional does not cover others",
+			pingers:     map[string]Pinger{"primary": healthy, "replica": closed, "cache": failing},
+			optional:    []string{"replica"},
+			wantStatus:  http.StatusServiceUnavailable,
+			wantCode:    "E9999",
+			wantMessage: "unhealthy: cache",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := serveHealth(t, GinHealthHandler(time.Second, tt.pingers, tt.optional...))
+			if status != tt.wantStatus || body.Code != tt.wantCode || body.Message != tt.wantMessage {
+				t.Errorf("got %d %s %q, want %d %s %q", status, body.Code, body.Message, tt.wantStatus, tt.wantCode, tt.wantMessage)
+			}
+			if len(body.Data) != len(tt.pingers) {
+				t.Fatalf("data = %v, want a result per pinger", body.Data)
+			}
+			if !body.Data["primary"].OK {
+				t.Errorf("primary = %+v, want ok", body.Data["primary"])
+			}
+			if replica, ok := body.Data["replica"]; ok && (replica.OK || replica.Error == "") {
+				t.Errorf("replica = %+v, want the failure reported", replica)
+			}
+		})
+	}
+}
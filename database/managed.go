@@ -0,0 +1,184 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/11SF/go-common/backoff"
+	"github.com/11SF/go-common/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// ConnectRetry retries the initial connection with backoff.Exponential
+// delays. MaxAttempts counts the first try; zero or one disables retries.
+// Every attempt initializes Config.Dial again and a failed attempt closes
+// its pool, so a dialector built around an existing *sql.DB (a Conn field)
+// cannot be retried.
+type ConnectRetry struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	// MaxBackoff caps the delay between attempts. Zero uses
+	// backoff.DefaultMax.
+	MaxBackoff time.Duration
+}
+
+type ManagedConfig struct {
+	Config
+	ConnectRetry ConnectRetry
+	// Replicas receive reads through gorm's dbresolver; writes and
+	// transactions stay on Config.Dial. Config.Pool applies to each of them.
+	Replicas []gorm.Dialector
+	// Logger records connection attempts. Nil uses logger.CreateLogger with
+	// the default config.
+	Logger *zap.Logger
+}
+
+// GormManager owns a *gorm.DB and the pools behind it.
+type GormManager struct {
+	db    *gorm.DB
+	pools []*sql.DB
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewManagedGorm connects with InitDatabaseCtx, retrying per
+// cfg.ConnectRetry until it succeeds, the attempts run out or ctx is done,
+// then registers cfg.Replicas for read/write splitting.
+func NewManagedGorm(ctx context.Context, cfg ManagedConfig) (*GormManager, error) {
+	log := cfg.Logger
+	if log == nil {
+		log = logger.CreateLogger(logger.Config{})
+	}
+
+	db, err := connectWithRetry(ctx, &cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cfg.Replicas) > 0 {
+		resolver := dbresolver.Register(dbresolver.Config{
+			Replicas: cfg.Replicas,
+			Policy:   dbresolver.RandomPolicy{},
+		})
+		if err := db.Use(resolver); err != nil {
+			closePools(collectPools(db))
+			return nil, fmt.Errorf("database: failed to register replicas: %w", err)
+		}
+	}
+
+	manager := &GormManager{db: db}
+	manager.pools = collectPools(db)
+	for _, pool := range manager.pools {
+		cfg.Pool.apply(pool)
+	}
+	return manager, nil
+}
+
+func connectWithRetry(ctx context.Context, cfg *ManagedConfig, log *zap.Logger) (*gorm.DB, error) {
+	maxAttempts := cfg.ConnectRetry.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		db, err := InitDatabaseCtx(ctx, &cfg.Config)
+		if err == nil {
+			if attempt > 1 {
+				log.Info("database connected", zap.Int("attempt", attempt))
+			}
+			return db, nil
+		}
+		if ctx.Err() != nil || attempt >= maxAttempts {
+			return nil, fmt.Errorf("database: connect failed after %d attempts: %w", attempt, err)
+		}
+
+		delay := backoff.Exponential(cfg.ConnectRetry.Backoff, cfg.ConnectRetry.MaxBackoff, attempt)
+		log.Warn("database connect failed, retrying",
+			zap.Int("attempt", attempt),
+			zap.Int("max_attempts", maxAttempts),
+			zap.Duration("backoff", delay),
+			zap.Error(err),
+		)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("database: connect failed after %d attempts: %w", attempt, ctx.Err())
+		}
+	}
+}
+
+// collectPools returns the primary pool and every replica pool of db.
+func collectPools(db *gorm.DB) []*sql.DB {
+	seen := make(map[*sql.DB]struct{})
+	var pools []*sql.DB
+	add := func(pool gorm.ConnPool) {
+		if sqlDB, ok := pool.(*sql.DB); ok {
+			if _, dup := seen[sqlDB]; !dup {
+				seen[sqlDB] = struct{}{}
+				pools = append(pools, sqlDB)
+			}
+		}
+	}
+
+	if sqlDB, err := db.DB(); err == nil {
+		add(sqlDB)
+	}
+	if plugin, ok := db.Config.Plugins[(&dbresolver.DBResolver{}).Name()]; ok {
+		plugin.(*dbresolver.DBResolver).Call(func(pool gorm.ConnPool) error {
+			add(pool)
+			return nil
+		})
+	}
+	return pools
+}
+
+func closePools(pools []*sql.DB) error {
+	var errs []error
+	for _, pool := range pools {
+		if err := pool.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (gm *GormManager) DB() *gorm.DB {
+	return gm.db
+}
+
+// Stats returns the primary pool's statistics.
+func (gm *GormManager) Stats() sql.DBStats {
+	if len(gm.pools) == 0 {
+		return sql.DBStats{}
+	}
+	return gm.pools[0].Stats()
+}
+
+// Close closes every pool, waiting for running queries to finish until ctx
+// is done. Later calls return the first result.
+func (gm *GormManager) Close(ctx context.Context) error {
+	gm.closeOnce.Do(func() {
+		done := make(chan error, 1)
+		go func() {
+			done <- closePools(gm.pools)
+		}()
+
+		select {
+		case err := <-done:
+			gm.closeErr = err
+		case <-ctx.Done():
+			gm.closeErr = fmt.Errorf("database: close did not finish: %w", ctx.Err())
+		}
+	})
+	return gm.closeErr
+}
@@ -0,0 +1,227 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/11SF/go-common/postgres"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+var quiet = gorm.Config{Logger: gormlogger.Discard}
+
+// flakyDialector fails Initialize until failures reaches zero.
+type flakyDialector struct {
+	gorm.Dialector
+	failures atomic.Int32
+	calls    atomic.Int32
+}
+
+func (d *flakyDialector) Initialize(db *gorm.DB) error {
+	d.calls.Add(1)
+	if d.failures.Add(-1) >= 0 {
+		return errors.New("connection refused")
+	}
+	return d.Dialector.Initialize(db)
+}
+
+func newSQLite(t *testing.T, name string) gorm.Dialector {
+	t.Helper()
+	dial, err := ConnectSQLite(filepath.Join(t.TempDir(), name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dial
+}
+
+func TestNewManagedGormRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int32
+		attempts  int
+		wantErr   string
+		wantCalls int32
+	}{
+		{"first try", 0, 3, "", 1},
+		{"retry then success", 2, 3, "", 3},
+		{"attempts exhausted", 5, 3, "database: connect failed after 3 attempts: connection refused", 3},
+		{"retries disabled", 1, 0, "database: connect failed after 1 attempts: connection refused", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dial := &flakyDialector{Dialector: newSQLite(t, "app.db")}
+			dial.failures.Store(tt.failures)
+
+			manager, err := NewManagedGorm(context.Background(), ManagedConfig{
+				Config:       Config{Dial: dial, GormConfig: quiet},
+				ConnectRetry: ConnectRetry{MaxAttempts: tt.attempts, Backoff: time.Millisecond},
+				Logger:       zap.NewNop(),
+			})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("NewManagedGorm() error = %v, want %q", err, tt.wantErr)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("NewManagedGorm() error = %v", err)
+				}
+				defer manager.Close(context.Background())
+			}
+			if got := dial.calls.Load(); got != tt.wantCalls {
+				t.Errorf("connect attempts = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// A PasswordProvider dialector owns its pool; each attempt must open a new
+// one instead of pinging the pool the previous attempt closed.
+func TestNewManagedGormRetryPasswordProvider(t *testing.T) {
+	var calls atomic.Int32
+	dial, err := postgres.ConnectPostgres(&postgres.Config{
+		Host:     "127.0.0.1",
+		Port:     1,
+		Username: "app",
+		DBName:   "orders",
+		PasswordProvider: func(context.Context) (string, time.Time, error) {
+			calls.Add(1)
+			return "", time.Time{}, errors.New("vault unavailable")
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = NewManagedGorm(context.Background(), ManagedConfig{
+		Config:       Config{Dial: dial, GormConfig: quiet},
+		ConnectRetry: ConnectRetry{MaxAttempts: 3, Backoff: time.Millisecond},
+		Logger:       zap.NewNop(),
+	})
+	if err == nil || !strings.Contains(err.Error(), "vault unavailable") {
+		t.Fatalf("NewManagedGorm() error = %v, want the provider error", err)
+	}
+	if got := calls.Load(); got < 3 {
+		t.Errorf("provider calls = %d, want at least 3", got)
+	}
+}
+
+func TestNewManagedGormCancelledDuringBackoff(t *testing.T) {
+	dial := &flakyDialector{Dialector: newSQLite(t, "app.db")}
+	dial.failures.Store(100)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := NewManagedGorm(ctx, ManagedConfig{
+		Config:       Config{Dial: dial, GormConfig: quiet},
+		ConnectRetry: ConnectRetry{MaxAttempts: 100, Backoff: time.Hour},
+		Logger:       zap.NewNop(),
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("NewManagedGorm() error = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("NewManagedGorm() returned after %s", elapsed)
+	}
+}
+
+type routedRow struct {
+	ID     uint
+	Source string
+}
+
+func TestNewManagedGormReplicas(t *testing.T) {
+	primaryDial := newSQLite(t, "primary.db")
+	replicaDial := newSQLite(t, "replica.db")
+
+	// Seed each file so reads show which one answered.
+	for _, seed := range []struct {
+		dial   gorm.Dialector
+		source string
+	}{{primaryDial, "primary"}, {replicaDial, "replica"}} {
+		db, err := gorm.Open(seed.dial, &quiet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Exec("CREATE TABLE routed_rows (id INTEGER PRIMARY KEY, source TEXT)").Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Create(&routedRow{Source: seed.source}).Error; err != nil {
+			t.Fatal(err)
+		}
+		sqlDB, _ := db.DB()
+		sqlDB.Close()
+	}
+
+	manager, err := NewManagedGorm(context.Background(), ManagedConfig{
+		Config:   Config{Dial: primaryDial, GormConfig: quiet, Pool: PoolConfig{MaxOpenConns: 3}},
+		Replicas: []gorm.Dialector{replicaDial},
+		Logger:   zap.NewNop(),
+	})
+	if err != nil {
+		t.Fatalf("NewManagedGorm() error = %v", err)
+	}
+	defer manager.Close(context.Background())
+	db := manager.DB()
+
+	var read routedRow
+	if err := db.First(&read).Error; err != nil {
+		t.Fatal(err)
+	}
+	if read.Source != "replica" {
+		t.Errorf("read from %s, want replica", read.Source)
+	}
+
+	if err := db.Create(&routedRow{Source: "written"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	if err := db.Model(&routedRow{}).Where("source = ?", "written").Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("replica has %d written rows, want 0", count)
+	}
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		return tx.Model(&routedRow{}).Where("source = ?", "written").Count(&count).Error
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("primary has %d written rows, want 1", count)
+	}
+
+	if got := len(manager.pools); got != 2 {
+		t.Errorf("pools = %d, want 2", got)
+	}
+	if got := manager.Stats().MaxOpenConnections; got != 3 {
+		t.Errorf("MaxOpenConnections = %d, want 3", got)
+	}
+}
+
+func TestGormManagerCloseIdempotent(t *testing.T) {
+	manager, err := NewManagedGorm(context.Background(), ManagedConfig{
+		Config: Config{Dial: newSQLite(t, "app.db"), GormConfig: quiet},
+		Logger: zap.NewNop(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := manager.Close(context.Background()); err != nil {
+			t.Fatalf("Close() #%d error = %v", i+1, err)
+		}
+	}
+	if err := manager.DB().Exec("SELECT 1").Error; err == nil || !strings.Contains(err.Error(), "closed") {
+		t.Errorf("query after Close error = %v, want closed", err)
+	}
+}
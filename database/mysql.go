@@ -0,0 +1,154 @@
+package database
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// MySQL defaults applied by BuildMySQLDSN.
+const (
+	DefaultMySQLPort    = 3306
+	DefaultMySQLCharset = "utf8mb4"
+	DefaultMySQLLoc     = "UTC"
+)
+
+type MySQLConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	DBName   string
+	// Charset defaults to DefaultMySQLCharset.
+	Charset string
+	// Loc is the time zone DATETIME values are read in; defaults to
+	// DefaultMySQLLoc. It is loaded on the client, so zones other than UTC
+	// and Local need tzdata in the image or an import of time/tzdata.
+	// parseTime is always enabled.
+	Loc string
+
+	ConnectTimeout time.Duration
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+
+	// TLS is the driver's tls parameter: "true", "skip-verify" or
+	// "preferred". It is ignored when CACertPEM is set.
+	TLS string
+	// CACertPEM registers a TLS profile trusting this CA and uses it.
+	CACertPEM string
+
+	// Params are extra connection parameters, such as session variables.
+	Params map[string]string
+}
+
+func ConnectMySQL(cf *MySQLConfig) (gorm.Dialector, error) {
+	dsn, err := BuildMySQLDSN(cf)
+	if err != nil {
+		return nil, err
+	}
+	dial := mysql.New(mysql.Config{DSN: dsn})
+	return dial, nil
+}
+
+// BuildMySQLDSN builds a go-sql-driver DSN from cf. Host, Username and
+// DBName are required.
+func BuildMySQLDSN(cf *MySQLConfig) (string, error) {
+	if cf == nil {
+		return "", errors.New("mysql: nil config")
+	}
+
+	var missing []string
+	if cf.Host == "" {
+		missing = append(missing, "Host")
+	}
+	if cf.Username == "" {
+		missing = append(missing, "Username")
+	}
+	if cf.DBName == "" {
+		missing = append(missing, "DBName")
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("mysql: missing required config %s", strings.Join(missing, ", "))
+	}
+	if cf.Port < 0 || cf.Port > math.MaxUint16 {
+		return "", fmt.Errorf("mysql: invalid port %d", cf.Port)
+	}
+
+	port := cf.Port
+	if port == 0 {
+		port = DefaultMySQLPort
+	}
+	charset := cf.Charset
+	if charset == "" {
+		charset = DefaultMySQLCharset
+	}
+	locName := cf.Loc
+	if locName == "" {
+		locName = DefaultMySQLLoc
+	}
+	loc, err := time.LoadLocation(locName)
+	if err != nil {
+		return "", fmt.Errorf("mysql: invalid Loc %q: %w", locName, err)
+	}
+
+	config := mysqldriver.NewConfig()
+	config.User = cf.Username
+	config.Passwd = cf.Password
+	config.Net = "tcp"
+	config.Addr = net.JoinHostPort(cf.Host, strconv.Itoa(port))
+	config.DBName = cf.DBName
+	config.ParseTime = true
+	config.Loc = loc
+	config.Timeout = cf.ConnectTimeout
+	config.ReadTimeout = cf.ReadTimeout
+	config.WriteTimeout = cf.WriteTimeout
+	config.Params = map[string]string{"charset": charset}
+	for key, value := range cf.Params {
+		config.Params[key] = value
+	}
+
+	switch {
+	case cf.CACertPEM != "":
+		name, err := registerMySQLCA(cf.CACertPEM)
+		if err != nil {
+			return "", err
+		}
+		config.TLSConfig = name
+	case cf.TLS != "":
+		config.TLSConfig = cf.TLS
+	}
+
+	return config.FormatDSN(), nil
+}
+
+// registerMySQLCA registers a TLS profile for pem under a name derived from
+// its contents, so the same CA always maps to the same profile.
+func registerMySQLCA(pem string) (string, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(pem)) {
+		return "", errors.New("mysql: no certificates found in CACertPEM")
+	}
+
+	sum := sha256.Sum256([]byte(pem))
+	name := "ca-" + hex.EncodeToString(sum[:8])
+	err := mysqldriver.RegisterTLSConfig(name, &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		RootCAs:    pool,
+	})
+	if err != nil {
+		return "", fmt.Errorf("mysql: failed to register TLS config: %w", err)
+	}
+	return name, nil
+}
@@ -0,0 +1,152 @@
+package database
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+)
+
+// testCAPEM returns a freshly generated self-signed CA certificate.
+func testCAPEM(t *testing.T) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestBuildMySQLDSN(t *testing.T) {
+	tests := []struct {
+		name string
+		cf   *MySQLConfig
+		want string
+	}{
+		{
+			name: "defaults",
+			cf:   &MySQLConfig{Host: "db.internal", Username: "app", Password: "secret", DBName: "orders"},
+			want: "app:secret@tcp(db.internal:3306)/orders?parseTime=true&charset=utf8mb4",
+		},
+		{
+			name: "all options",
+			cf: &MySQLConfig{
+				Host:           "db.internal",
+				Port:           3307,
+				Username:       "app",
+				Password:       "p@ss:w/rd",
+				DBName:         "orders",
+				Charset:        "utf8",
+				Loc:            "Local",
+				ConnectTimeout: 5 * time.Second,
+				ReadTimeout:    30 * time.Second,
+				WriteTimeout:   time.Minute,
+				TLS:            "skip-verify",
+				Params:         map[string]string{"sql_mode": "'TRADITIONAL'"},
+			},
+			want: "app:p@ss:w/rd@tcp(db.internal:3307)/orders?loc=Local&parseTime=true&readTimeout=30s&timeout=5s&tls=skip-verify&writeTimeout=1m0s&charset=utf8&sql_mode=%27TRADITIONAL%27",
+		},
+		{
+			name: "IPv6 host",
+			cf:   &MySQLConfig{Host: "::1", Username: "app", DBName: "orders"},
+			want: "app@tcp([::1]:3306)/orders?parseTime=true&charset=utf8mb4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dsn, err := BuildMySQLDSN(tt.cf)
+			if err != nil {
+				t.Fatalf("BuildMySQLDSN() error = %v", err)
+			}
+			if dsn != tt.want {
+				t.Errorf("BuildMySQLDSN() =\n%s\nwant\n%s", dsn, tt.want)
+			}
+			if _, err := mysqldriver.ParseDSN(dsn); err != nil {
+				t.Errorf("ParseDSN(%q) error = %v", dsn, err)
+			}
+		})
+	}
+}
+
+func TestBuildMySQLDSNCACert(t *testing.T) {
+	caPEM := testCAPEM(t)
+	cf := &MySQLConfig{Host: "db", Username: "app", DBName: "orders", TLS: "true", CACertPEM: caPEM}
+
+	first, err := BuildMySQLDSN(cf)
+	if err != nil {
+		t.Fatalf("BuildMySQLDSN() error = %v", err)
+	}
+	if !regexp.MustCompile(`[?&]tls=ca-[0-9a-f]{16}(&|$)`).MatchString(first) {
+		t.Errorf("DSN %q does not use a ca- TLS profile", first)
+	}
+	second, err := BuildMySQLDSN(cf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Errorf("same CA produced different DSNs:\n%s\n%s", first, second)
+	}
+
+	parsed, err := mysqldriver.ParseDSN(first)
+	if err != nil {
+		t.Fatalf("ParseDSN() error = %v", err)
+	}
+	if parsed.TLS == nil || parsed.TLS.RootCAs == nil {
+		t.Error("parsed DSN has no registered TLS config with root CAs")
+	}
+}
+
+func TestBuildMySQLDSNValidation(t *testing.T) {
+	valid := func() *MySQLConfig {
+		return &MySQLConfig{Host: "db", Username: "app", DBName: "orders"}
+	}
+	with := func(fn func(*MySQLConfig)) *MySQLConfig {
+		cf := valid()
+		fn(cf)
+		return cf
+	}
+
+	tests := []struct {
+		name    string
+		cf      *MySQLConfig
+		wantErr string
+	}{
+		{"nil config", nil, "mysql: nil config"},
+		{"missing fields", &MySQLConfig{}, "mysql: missing required config Host, Username, DBName"},
+		{"invalid port", with(func(cf *MySQLConfig) { cf.Port = 65536 }), "mysql: invalid port 65536"},
+		{"invalid loc", with(func(cf *MySQLConfig) { cf.Loc = "Mars/Olympus" }), `mysql: invalid Loc "Mars/Olympus"`},
+		{"invalid CA", with(func(cf *MySQLConfig) { cf.CACertPEM = "not a cert" }), "mysql: no certificates found in CACertPEM"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := BuildMySQLDSN(tt.cf)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Fatalf("BuildMySQLDSN() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// SQLiteMemory opens an in-memory database. It uses a shared cache, so
+// every connection in the process sees the same database.
+const SQLiteMemory = ":memory:"
+
+type sqliteOptions struct {
+	busyTimeout time.Duration
+	journalMode string
+	foreignKeys bool
+}
+
+type SQLiteOption func(*sqliteOptions)
+
+// SQLiteBusyTimeout waits up to d for a locked database instead of failing
+// immediately.
+func SQLiteBusyTimeout(d time.Duration) SQLiteOption {
+	return func(o *sqliteOptions) {
+		o.busyTimeout = d
+	}
+}
+
+// SQLiteJournalMode sets the journal mode, such as "WAL".
+func SQLiteJournalMode(mode string) SQLiteOption {
+	return func(o *sqliteOptions) {
+		o.journalMode = mode
+	}
+}
+
+// SQLiteForeignKeys enables foreign key enforcement, which SQLite leaves off
+// by default.
+func SQLiteForeignKeys() SQLiteOption {
+	return func(o *sqliteOptions) {
+		o.foreignKeys = true
+	}
+}
+
+// ConnectSQLite opens the database file at path, or an in-memory database
+// when path is SQLiteMemory.
+func ConnectSQLite(path string, opts ...SQLiteOption) (gorm.Dialector, error) {
+	dsn, err := BuildSQLiteDSN(path, opts...)
+	if err != nil {
+		return nil, err
+	}
+	dial := sqlite.Open(dsn)
+	return dial, nil
+}
+
+// BuildSQLiteDSN builds a go-sqlite3 file URI for path.
+func BuildSQLiteDSN(path string, opts ...SQLiteOption) (string, error) {
+	if path == "" {
+		return "", errors.New("sqlite: path is required")
+	}
+	if strings.ContainsAny(path, "?#") {
+		return "", errors.New("sqlite: path must not contain '?' or '#'")
+	}
+
+	var options sqliteOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	params := url.Values{}
+	if path == SQLiteMemory {
+		params.Set("cache", "shared")
+	}
+	if options.busyTimeout > 0 {
+		params.Set("_busy_timeout", strconv.FormatInt(options.busyTimeout.Milliseconds(), 10))
+	}
+	if options.journalMode != "" {
+		params.Set("_journal_mode", options.journalMode)
+	}
+	if options.foreignKeys {
+		params.Set("_foreign_keys", "on")
+	}
+
+	dsn := "file:" + path
+	if len(params) > 0 {
+		dsn += "?" + params.Encode()
+	}
+	return dsn, nil
+}
@@ -0,0 +1,127 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestBuildSQLiteDSN(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		opts    []SQLiteOption
+		want    string
+		wantErr string
+	}{
+		{name: "file", path: "/var/lib/app.db", want: "file:/var/lib/app.db"},
+		{name: "memory", path: SQLiteMemory, want: "file::memory:?cache=shared"},
+		{
+			name: "options",
+			path: "app.db",
+			opts: []SQLiteOption{SQLiteBusyTimeout(5 * time.Second), SQLiteJournalMode("WAL"), SQLiteForeignKeys()},
+			want: "file:app.db?_busy_timeout=5000&_foreign_keys=on&_journal_mode=WAL",
+		},
+		{name: "empty path", path: "", wantErr: "sqlite: path is required"},
+		{name: "query in path", path: "app.db?mode=ro", wantErr: "sqlite: path must not contain '?' or '#'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dsn, err := BuildSQLiteDSN(tt.path, tt.opts...)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("BuildSQLiteDSN() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildSQLiteDSN() error = %v", err)
+			}
+			if dsn != tt.want {
+				t.Errorf("BuildSQLiteDSN() = %q, want %q", dsn, tt.want)
+			}
+		})
+	}
+}
+
+func TestConnectSQLite(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"file", filepath.Join(t.TempDir(), "app.db")},
+		{"memory", SQLiteMemory},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dial, err := ConnectSQLite(tt.path, SQLiteBusyTimeout(time.Second), SQLiteJournalMode("WAL"), SQLiteForeignKeys())
+			if err != nil {
+				t.Fatalf("ConnectSQLite() error = %v", err)
+			}
+			db, err := InitDatabaseCtx(context.Background(), &Config{Dial: dial, GormConfig: quiet})
+			if err != nil {
+				t.Fatalf("InitDatabaseCtx() error = %v", err)
+			}
+			sqlDB, _ := db.DB()
+			defer sqlDB.Close()
+
+			pragma := func(name string) string {
+				var value string
+				if err := db.Raw("PRAGMA " + name).Scan(&value).Error; err != nil {
+					t.Fatalf("PRAGMA %s error = %v", name, err)
+				}
+				return value
+			}
+			if got := pragma("foreign_keys"); got != "1" {
+				t.Errorf("foreign_keys = %s, want 1", got)
+			}
+			if got := pragma("busy_timeout"); got != "1000" {
+				t.Errorf("busy_timeout = %s, want 1000", got)
+			}
+
+			if err := db.Exec("CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)").Error; err != nil {
+				t.Fatal(err)
+			}
+			if err := db.Exec("INSERT INTO items (name) VALUES (?)", "a").Error; err != nil {
+				t.Fatal(err)
+			}
+			var count int64
+			if err := db.Table("items").Count(&count).Error; err != nil {
+				t.Fatal(err)
+			}
+			if count != 1 {
+				t.Errorf("count = %d, want 1", count)
+			}
+		})
+	}
+}
+
+func TestConnectSQLiteMemoryShared(t *testing.T) {
+	open := func() *gorm.DB {
+		dial, err := ConnectSQLite(SQLiteMemory)
+		if err != nil {
+			t.Fatal(err)
+		}
+		db, err := InitDatabaseCtx(context.Background(), &Config{Dial: dial, GormConfig: quiet})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sqlDB, _ := db.DB()
+		t.Cleanup(func() { sqlDB.Close() })
+		return db
+	}
+
+	first, second := open(), open()
+	if err := first.Exec("CREATE TABLE shared (id INTEGER)").Error; err != nil {
+		t.Fatal(err)
+	}
+	defer first.Exec("DROP TABLE shared")
+	if err := second.Exec("INSERT INTO shared VALUES (1)").Error; err != nil {
+		t.Errorf("second connection does not see the table: %v", err)
+	}
+}
@@ -0,0 +1,245 @@
+// Package health aggregates dependency checks into liveness and readiness
+// endpoints.
+//
+//	reg := health.New()
+//	reg.Register("postgres", true, health.GormCheck(db))
+//	reg.Register("kafka", false, health.KafkaCheck(kafkaConfig))
+//	r.GET("/livez", reg.LivenessHandler())
+//	r.GET("/readyz", reg.ReadinessHandler())
+//	r.GET("/healthz", reg.Handler())
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/11SF/go-common/database"
+	"github.com/11SF/go-common/kafka"
+	"github.com/11SF/go-common/response"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Defaults applied by New.
+const (
+	DefaultCheckTimeout = 2 * time.Second
+	DefaultCacheTTL     = 2 * time.Second
+)
+
+// CheckFunc reports a dependency as healthy by returning nil.
+type CheckFunc func(ctx context.Context) error
+
+// PingContext lets a CheckFunc be used as a database.Pinger.
+func (f CheckFunc) PingContext(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Recorder receives every check result, for example to export it as a
+// gauge. Implementations must be safe for concurrent use.
+type Recorder interface {
+	SetCheck(name string, ok bool, latency time.Duration)
+}
+
+type CheckResult struct {
+	database.HealthResult
+	Critical bool `json:"critical"`
+}
+
+type Report struct {
+	Healthy bool                   `json:"healthy"`
+	Checks  map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type check struct {
+	critical bool
+	fn       CheckFunc
+}
+
+type cachedReport struct {
+	report Report
+	at     time.Time
+}
+
+// Registry holds named checks and caches their results.
+type Registry struct {
+	timeout  time.Duration
+	ttl      time.Duration
+	recorder Recorder
+	now      func() time.Time
+
+	mu     sync.RWMutex
+	checks map[string]check
+
+	// runMu serialises runs so concurrent probes share one result.
+	runMu sync.Mutex
+	cache map[bool]cachedReport
+}
+
+type Option func(*Registry)
+
+// WithCheckTimeout bounds each check. Defaults to DefaultCheckTimeout.
+func WithCheckTimeout(d time.Duration) Option {
+	return func(r *Registry) {
+		r.timeout = d
+	}
+}
+
+// WithCacheTTL reuses results for d so probe storms do not reach the
+// dependencies. Zero disables caching. Defaults to DefaultCacheTTL.
+func WithCacheTTL(d time.Duration) Option {
+	return func(r *Registry) {
+		r.ttl = d
+	}
+}
+
+// WithRecorder reports every check result to rec.
+func WithRecorder(rec Recorder) Option {
+	return func(r *Registry) {
+		r.recorder = rec
+	}
+}
+
+func New(opts ...Option) *Registry {
+	r := &Registry{
+		timeout: DefaultCheckTimeout,
+		ttl:     DefaultCacheTTL,
+		now:     time.Now,
+		checks:  make(map[string]check),
+		cache:   make(map[bool]cachedReport),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Register adds or replaces the check called name. Only critical checks
+// decide the overall status.
+func (r *Registry) Register(name string, critical bool, fn CheckFunc) {
+	r.mu.Lock()
+	r.checks[name] = check{critical: critical, fn: fn}
+	r.mu.Unlock()
+
+	r.runMu.Lock()
+	clear(r.cache)
+	r.runMu.Unlock()
+}
+
+// Check runs every check concurrently, or only the critical ones when
+// criticalOnly is set, reusing results younger than the cache TTL. Checks
+// are not cancelled with ctx, and a report produced after ctx ended is
+// returned but not cached.
+func (r *Registry) Check(ctx context.Context, criticalOnly bool) Report {
+	r.runMu.Lock()
+	defer r.runMu.Unlock()
+
+	if cached, ok := r.cache[criticalOnly]; ok && r.ttl > 0 && r.now().Sub(cached.at) < r.ttl {
+		return cached.report
+	}
+
+	r.mu.RLock()
+	checks := make(map[string]check, len(r.checks))
+	pingers := make(map[string]database.Pinger, len(r.checks))
+	for name, c := range r.checks {
+		if criticalOnly && !c.critical {
+			continue
+		}
+		checks[name] = c
+		pingers[name] = c.fn
+	}
+	r.mu.RUnlock()
+
+	// Results are shared with other probes, so one caller going away must
+	// not fail the checks for everyone; each check is bounded by the
+	// timeout instead.
+	checkCtx := ctx
+	if r.timeout > 0 {
+		checkCtx = context.WithoutCancel(ctx)
+	}
+	results := database.CheckAll(checkCtx, r.timeout, pingers)
+	report := Report{Healthy: true, Checks: make(map[string]CheckResult, len(results))}
+	for name, result := range results {
+		critical := checks[name].critical
+		report.Checks[name] = CheckResult{HealthResult: result, Critical: critical}
+		if critical && !result.OK {
+			report.Healthy = false
+		}
+		if r.recorder != nil {
+			r.recorder.SetCheck(name, result.OK, result.Latency)
+		}
+	}
+
+	if ctx.Err() == nil {
+		r.cache[criticalOnly] = cachedReport{report: report, at: r.now()}
+	}
+	return report
+}
+
+// Handler runs every check and responds 200, or 503 when a critical check
+// fails.
+func (r *Registry) Handler() gin.HandlerFunc {
+	return r.handler(false)
+}
+
+// ReadinessHandler is Handler restricted to critical checks.
+func (r *Registry) ReadinessHandler() gin.HandlerFunc {
+	return r.handler(true)
+}
+
+// LivenessHandler always responds 200 without running checks; a process
+// that can answer is alive even when its dependencies are not.
+func (r *Registry) LivenessHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response.NewGinResponse(c, http.StatusOK, Report{Healthy: true})
+	}
+}
+
+func (r *Registry) handler(criticalOnly bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := r.Check(c.Request.Context(), criticalOnly)
+		if !report.Healthy {
+			response.NewGinResponseWithCode(c, http.StatusServiceUnavailable, response.GenericError, unhealthyMessage(report), report)
+			return
+		}
+		response.NewGinResponse(c, http.StatusOK, report)
+	}
+}
+
+func unhealthyMessage(report Report) string {
+	var failed []string
+	for name, result := range report.Checks {
+		if result.Critical && !result.OK {
+			failed = append(failed, name)
+		}
+	}
+	sort.Strings(failed)
+	return fmt.Sprintf("unhealthy: %s", strings.Join(failed, ", "))
+}
+
+// PingerCheck checks anything with PingContext, such as *sql.DB.
+func PingerCheck(p database.Pinger) CheckFunc {
+	return p.PingContext
+}
+
+// GormCheck pings the sql.DB behind db.
+func GormCheck(db *gorm.DB) CheckFunc {
+	return database.WrapGorm(db).PingContext
+}
+
+// KafkaCheck fetches broker metadata with kafka.HealthCheck, failing only
+// when no broker is reachable. It uses the check's deadline as the timeout.
+func KafkaCheck(cf *kafka.Config) CheckFunc {
+	return func(ctx context.Context) error {
+		timeout := DefaultCheckTimeout
+		if deadline, ok := ctx.Deadline(); ok {
+			timeout = time.Until(deadline)
+		}
+		_, err := kafka.HealthCheck(ctx, cf, timeout)
+		return err
+	}
+}
@@ -0,0 +1,209 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// countingCheck returns a check that counts its calls and returns err.
+func countingCheck(calls *atomic.Int32, err error) CheckFunc {
+	return func(context.Context) error {
+		calls.Add(1)
+		return err
+	}
+}
+
+type testCheck struct {
+	critical bool
+	err      error
+}
+
+func TestCheckCriticality(t *testing.T) {
+	errDown := errors.New("down")
+
+	tests := []struct {
+		name         string
+		criticalOnly bool
+		checks       map[string]testCheck
+		wantHealthy  bool
+		wantChecks   []string
+	}{
+		{
+			name: "all passing",
+			checks: map[string]testCheck{
+				"db":    {critical: true},
+				"cache": {critical: false},
+			},
+			wantHealthy: true,
+			wantChecks:  []string{"db", "cache"},
+		},
+		{
+			name: "non-critical failure",
+			checks: map[string]testCheck{
+				"db":    {critical: true},
+				"cache": {critical: false, err: errDown},
+			},
+			wantHealthy: true,
+			wantChecks:  []string{"db", "cache"},
+		},
+		{
+			name: "critical failure",
+			checks: map[string]testCheck{
+				"db":    {critical: true, err: errDown},
+				"cache": {critical: false},
+			},
+			wantHealthy: false,
+			wantChecks:  []string{"db", "cache"},
+		},
+		{
+			name:         "critical only skips non-critical",
+			criticalOnly: true,
+			checks: map[string]testCheck{
+				"db":    {critical: true},
+				"cache": {critical: false, err: errDown},
+			},
+			wantHealthy: true,
+			wantChecks:  []string{"db"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := New(WithCacheTTL(0))
+			for name, c := range tt.checks {
+				reg.Register(name, c.critical, func(context.Context) error { return c.err })
+			}
+
+			report := reg.Check(context.Background(), tt.criticalOnly)
+			if report.Healthy != tt.wantHealthy {
+				t.Errorf("Healthy = %v, want %v", report.Healthy, tt.wantHealthy)
+			}
+			if len(report.Checks) != len(tt.wantChecks) {
+				t.Fatalf("got %d checks, want %d: %+v", len(report.Checks), len(tt.wantChecks), report.Checks)
+			}
+			for _, name := range tt.wantChecks {
+				result, ok := report.Checks[name]
+				if !ok {
+					t.Fatalf("missing check %q", name)
+				}
+				want := tt.checks[name]
+				if result.OK != (want.err == nil) || result.Critical != want.critical {
+					t.Errorf("%s = %+v, want ok=%v critical=%v", name, result, want.err == nil, want.critical)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckCache(t *testing.T) {
+	var calls atomic.Int32
+	now := time.Unix(0, 0)
+	reg := New(WithCacheTTL(time.Second))
+	reg.now = func() time.Time { return now }
+	reg.Register("db", true, countingCheck(&calls, nil))
+
+	reg.Check(context.Background(), false)
+	now = now.Add(999 * time.Millisecond)
+	reg.Check(context.Background(), false)
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("calls within TTL = %d, want 1", got)
+	}
+
+	// The critical-only report is cached separately.
+	reg.Check(context.Background(), true)
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("calls after criticalOnly = %d, want 2", got)
+	}
+
+	now = now.Add(time.Millisecond)
+	reg.Check(context.Background(), false)
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("calls after TTL = %d, want 3", got)
+	}
+
+	// Register invalidates the cache.
+	reg.Register("cache", false, countingCheck(&calls, nil))
+	reg.Check(context.Background(), false)
+	if got := calls.Load(); got != 5 {
+		t.Fatalf("calls after Register = %d, want 5", got)
+	}
+}
+
+func TestCheckCancelledCaller(t *testing.T) {
+	var calls atomic.Int32
+	reg := New(WithCacheTTL(time.Minute))
+	reg.Register("db", true, func(ctx context.Context) error {
+		calls.Add(1)
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if report := reg.Check(ctx, false); !report.Healthy {
+		t.Fatalf("check failed with the caller's cancellation: %+v", report)
+	}
+
+	// A report produced for a cancelled caller is not cached.
+	reg.Check(context.Background(), false)
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("calls = %d, want 2", got)
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	reg := New(WithCheckTimeout(10*time.Millisecond), WithCacheTTL(0))
+	reg.Register("slow", true, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	report := reg.Check(context.Background(), false)
+	if report.Healthy {
+		t.Fatal("Healthy = true, want false")
+	}
+	if got := report.Checks["slow"].Error; got != context.DeadlineExceeded.Error() {
+		t.Errorf("Error = %q, want %q", got, context.DeadlineExceeded.Error())
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reg := New(WithCacheTTL(0))
+	reg.Register("db", true, func(context.Context) error { return nil })
+	reg.Register("cache", false, func(context.Context) error { return errors.New("down") })
+
+	router := gin.New()
+	router.GET("/healthz", reg.Handler())
+	router.GET("/readyz", reg.ReadinessHandler())
+	router.GET("/livez", reg.LivenessHandler())
+
+	serve := func(path string) int {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code
+	}
+
+	for _, path := range []string{"/healthz", "/readyz", "/livez"} {
+		if got := serve(path); got != http.StatusOK {
+			t.Errorf("%s = %d, want %d", path, got, http.StatusOK)
+		}
+	}
+
+	reg.Register("db", true, func(context.Context) error { return errors.New("down") })
+	for path, want := range map[string]int{
+		"/healthz": http.StatusServiceUnavailable,
+		"/readyz":  http.StatusServiceUnavailable,
+		"/livez":   http.StatusOK,
+	} {
+		if got := serve(path); got != want {
+			t.Errorf("%s = %d, want %d", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// ErrRequestBuilderUsed is returned when Do is called twice on the same
+// RequestBuilder.
+var ErrRequestBuilderUsed = errors.New("request builder has already been used")
+
+// RequestBuilder assembles a single request fluently:
+//
+//	resp, err := client.NewRequest("POST", "/v1/orders").
+//		Query("expand", "items").
+//		Header("Idempotency-Key", key).
+//		JSONBody(order).
+//		Timeout(10 * time.Second).
+//		Do(ctx)
+//
+// A builder is single-use and must not be shared between goroutines.
+type RequestBuilder struct {
+	hc          *HTTPClient
+	method      string
+	endpoint    string
+	query       []queryParam
+	headers     map[string]string
+	body        []byte
+	contentType string
+	opts        []RequestOption
+	err         error
+	used        atomic.Bool
+}
+
+// NewRequest starts building a request for method and endpoint.
+func (hc *HTTPClient) NewRequest(method, endpoint string) *RequestBuilder {
+	return &RequestBuilder{
+		hc:       hc,
+		method:   strings.ToUpper(method),
+		endpoint: endpoint,
+		headers:  make(map[string]string),
+	}
+}
+
+// Query adds a query parameter. Repeated keys are sent repeatedly.
+func (rb *RequestBuilder) Query(key, value string) *RequestBuilder {
+	rb.query = append(rb.query, queryParam{key: key, value: value})
+	return rb
+}
+
+// QueryStruct adds query parameters encoded from a struct, as GetWithQuery.
+func (rb *RequestBuilder) QueryStruct(params any) *RequestBuilder {
+	query, err := encodeQuery(params)
+	if err != nil {
+		rb.setErr(fmt.Errorf("failed to encode query params: %w", err))
+		return rb
+	}
+	rb.query = append(rb.query, query...)
+	return rb
+}
+
+// Header sets a request header, overriding the client defaults.
+func (rb *RequestBuilder) Header(key, value string) *RequestBuilder {
+	rb.headers[http.CanonicalHeaderKey(key)] = value
+	return rb
+}
+
+// JSONBody marshals v as the request body with ClientConfig.ContentType,
+// or application/json when none is configured.
+func (rb *RequestBuilder) JSONBody(v any) *RequestBuilder {
+	data, err := json.Marshal(v)
+	if err != nil {
+		rb.setErr(fmt.Errorf("failed to marshal request body: %w", err))
+		return rb
+	}
+
+	contentType := rb.hc.config.ContentType
+	if contentType == "" {
+		contentType = ContentTypeJSON
+	}
+	return rb.Body(data, contentType)
+}
+
+// Body sets a raw request body and its content type.
+func (rb *RequestBuilder) Body(body []byte, contentType string) *RequestBuilder {
+	rb.body = body
+	rb.contentType = contentType
+	return rb
+}
+
+// Timeout overrides ClientConfig.Timeout for this request.
+func (rb *RequestBuilder) Timeout(timeout time.Duration) *RequestBuilder {
+	return rb.Options(WithTimeout(timeout))
+}
+
+// BaseURL sends this request to baseURL instead of ClientConfig.BaseURL.
+func (rb *RequestBuilder) BaseURL(baseURL string) *RequestBuilder {
+	return rb.Options(WithBaseURL(baseURL))
+}
+
+// Options applies per-request options.
+func (rb *RequestBuilder) Options(opts ...RequestOption) *RequestBuilder {
+	rb.opts = append(rb.opts, opts...)
+	return rb
+}
+
+func (rb *RequestBuilder) setErr(err error) {
+	if rb.err == nil {
+		rb.err = err
+	}
+}
+
+// Do sends the request. It fails with ErrRequestBuilderUsed when called more
+// than once.
+func (rb *RequestBuilder) Do(ctx context.Context) (*Response, error) {
+	if !rb.used.CompareAndSwap(false, true) {
+		return nil, ErrRequestBuilderUsed
+	}
+	if rb.err != nil {
+		return nil, rb.err
+	}
+
+	options := newRequestOptions(rb.opts)
+	url, err := rb.hc.requestURL(rb.endpoint, options)
+	if err != nil {
+		return nil, err
+	}
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+
+	req.SetRequestURI(url)
+	for _, param := range rb.query {
+		req.URI().QueryArgs().Add(param.key, param.value)
+	}
+	req.Header.SetMethod(rb.method)
+	if rb.body != nil {
+		req.Header.SetContentType(rb.contentType)
+		req.SetBody(rb.body)
+	}
+
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	response, err := rb.hc.doRequest(ctx, req, resp, rb.headers, options)
+	if err != nil {
+		return requestError(rb.method, response, err)
+	}
+
+	return response, nil
+}
+
+// DoJSON sends the request built by rb and decodes the response body into T.
+func DoJSON[T any](ctx context.Context, rb *RequestBuilder) (T, *Response, error) {
+	var out T
+
+	response, err := rb.Do(ctx)
+	if err != nil {
+		return out, response, err
+	}
+	if err := json.Unmarshal(response.Body, &out); err != nil {
+		return out, response, fmt.Errorf("failed to unmarshal response body: %w", err)
+	}
+
+	return out, response, nil
+}
@@ -0,0 +1,150 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// echoedRequest is what newEchoServer reports about each request.
+type echoedRequest struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Query       string `json:"query"`
+	ContentType string `json:"content_type"`
+	APIKey      string `json:"api_key"`
+	Body        string `json:"body"`
+}
+
+func newEchoServer(t *testing.T, hits *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		json.NewEncoder(w).Encode(echoedRequest{
+			Method:      r.Method,
+			Path:        r.URL.Path,
+			Query:       r.URL.RawQuery,
+			ContentType: r.Header.Get("Content-Type"),
+			APIKey:      r.Header.Get("X-Api-Key"),
+			Body:        string(body),
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRequestBuilder(t *testing.T) {
+	var hits atomic.Int32
+	srv := newEchoServer(t, &hits)
+	hc := NewHTTPClient(ClientConfig{
+		BaseURL:        srv.URL,
+		Timeout:        5 * time.Second,
+		DefaultHeaders: map[string]string{"X-Api-Key": "default"},
+	})
+
+	tests := []struct {
+		name  string
+		build func() *RequestBuilder
+		want  echoedRequest
+	}{
+		{
+			name:  "plain",
+			build: func() *RequestBuilder { return hc.NewRequest("get", "/orders") },
+			want:  echoedRequest{Method: "GET", Path: "/orders", APIKey: "default"},
+		},
+		{
+			name: "repeated query and struct query",
+			build: func() *RequestBuilder {
+				return hc.NewRequest(http.MethodGet, "/orders").
+					Query("tag", "a").
+					Query("tag", "b").
+					QueryStruct(queryPage{Page: 2})
+			},
+			want: echoedRequest{Method: "GET", Path: "/orders", Query: "tag=a&tag=b&page=2", APIKey: "default"},
+		},
+		{
+			name: "header overrides default",
+			build: func() *RequestBuilder {
+				return hc.NewRequest(http.MethodGet, "/orders/1").Header("x-api-key", "override")
+			},
+			want: echoedRequest{Method: "GET", Path: "/orders/1", APIKey: "override"},
+		},
+		{
+			name: "json body",
+			build: func() *RequestBuilder {
+				return hc.NewRequest(http.MethodPost, "/orders").
+					Query("dry_run", "true").
+					JSONBody(map[string]int{"qty": 2})
+			},
+			want: echoedRequest{Method: "POST", Path: "/orders", Query: "dry_run=true", ContentType: "application/json", APIKey: "default", Body: `{"qty":2}`},
+		},
+		{
+			name: "raw body",
+			build: func() *RequestBuilder {
+				return hc.NewRequest(http.MethodPut, "/orders/1").Body([]byte("a,b"), "text/csv")
+			},
+			want: echoedRequest{Method: "PUT", Path: "/orders/1", ContentType: "text/csv", APIKey: "default", Body: "a,b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := DoJSON[echoedRequest](context.Background(), tt.build())
+			if err != nil {
+				t.Fatalf("DoJSON() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("server saw %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestBuilderErrors(t *testing.T) {
+	var hits atomic.Int32
+	srv := newEchoServer(t, &hits)
+	hc := NewHTTPClient(ClientConfig{BaseURL: srv.URL, Timeout: 5 * time.Second})
+
+	t.Run("used twice", func(t *testing.T) {
+		rb := hc.NewRequest(http.MethodGet, "/")
+		if _, err := rb.Do(context.Background()); err != nil {
+			t.Fatalf("first Do() error = %v", err)
+		}
+		if _, err := rb.Do(context.Background()); !errors.Is(err, ErrRequestBuilderUsed) {
+			t.Errorf("second Do() error = %v, want ErrRequestBuilderUsed", err)
+		}
+	})
+
+	t.Run("build errors are not sent", func(t *testing.T) {
+		hits.Store(0)
+		_, err := hc.NewRequest(http.MethodPost, "/").JSONBody(make(chan int)).Do(context.Background())
+		var jsonErr *json.UnsupportedTypeError
+		if !errors.As(err, &jsonErr) {
+			t.Errorf("Do() with an unmarshalable body error = %v, want *json.UnsupportedTypeError", err)
+		}
+		_, err = hc.NewRequest(http.MethodGet, "/").QueryStruct("not a struct").Do(context.Background())
+		if err == nil {
+			t.Error("Do() with invalid query params succeeded")
+		}
+		if n := hits.Load(); n != 0 {
+			t.Errorf("server hit %d times, want 0", n)
+		}
+	})
+
+	t.Run("DoJSON decode error", func(t *testing.T) {
+		_, resp, err := DoJSON[[]int](context.Background(), hc.NewRequest(http.MethodGet, "/"))
+		if err == nil {
+			t.Fatal("DoJSON() into the wrong type succeeded")
+		}
+		if resp == nil || resp.StatusCode != http.StatusOK {
+			t.Errorf("DoJSON() response = %+v, want the 200 response", resp)
+		}
+	})
+}
@@ -1,11 +1,17 @@
 package httpclient
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"sync"
 	"time"
 
 	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
 )
 
 // ClientConfig holds the configuration for the HTTP client.
@@ -13,25 +19,338 @@ type ClientConfig struct {
 	BaseURL     string
 	Timeout     time.Duration
 	ContentType string
+	// DefaultHeaders are sent with every request. Per-request headers with
+	// the same key, compared case-insensitively, take precedence.
+	DefaultHeaders map[string]string
+	// RequestInterceptors run in order before each request is sent. They may
+	// mutate the RequestInfo; an error aborts the call.
+	RequestInterceptors []func(req *RequestInfo) error
+	// ResponseInterceptors run in order after each response is received.
+	ResponseInterceptors []func(req *RequestInfo, resp *Response) error
+	// EnableLogging emits one structured record per request through Logger,
+	// or a debug-level logger from the logger package when Logger is nil.
+	EnableLogging bool
+	LogOptions    LogOptions
+	Logger        *zap.Logger
+	// ErrorOnNon2xx makes the Response-returning methods return an
+	// *HTTPError for statuses of 400 and above. Get, Post and Delete are not
+	// affected.
+	ErrorOnNon2xx bool
+	// EnableCookies keeps cookies from Set-Cookie responses and sends them on
+	// later requests to matching hosts and paths.
+	EnableCookies bool
+	// EnableCompression requests gzip, deflate or brotli responses and
+	// decodes them, including streamed downloads, before returning.
+	EnableCompression bool
+	// FollowRedirects follows 3xx responses up to MaxRedirects (10 when
+	// zero). Authorization is dropped on cross-host redirects unless
+	// RedirectForwardAuth is set, and 307/308 redirects of requests with a
+	// body are only followed when RedirectResendBody is set.
+	FollowRedirects     bool
+	MaxRedirects        int
+	RedirectForwardAuth bool
+	RedirectResendBody  bool
+	// Metrics, when set, records duration, status and in-flight counts for
+	// every request.
+	Metrics MetricsRecorder
 }
 
 // HTTPClient wraps fasthttp.Client with custom configuration.
 type HTTPClient struct {
 	client *fasthttp.Client
 	config ClientConfig
+	// streamClient shares settings with client but streams response bodies
+	// larger than streamBufferSize instead of buffering them.
+	streamClient *fasthttp.Client
+	reqLogger    *requestLogger
+
+	// mu guards the fields below. defaultHeaders is keyed by canonical
+	// header name and replaced, never mutated, so readers may range over a
+	// snapshot without holding mu.
+	mu             sync.RWMutex
+	defaultHeaders map[string]string
+	jar            *cookiejar.Jar
+}
+
+// Option configures an HTTPClient at construction time.
+type Option func(*HTTPClient)
+
+// WithBearerToken sets a default "Authorization: Bearer <token>" header.
+func WithBearerToken(token string) Option {
+	return func(hc *HTTPClient) {
+		hc.SetDefaultHeader(HeaderAuthorization, "Bearer "+token)
+	}
+}
+
+// WithBasicAuth sets a default HTTP basic Authorization header.
+func WithBasicAuth(user, pass string) Option {
+	return func(hc *HTTPClient) {
+		cred := base64.StdEncoding.EncodeToString([]byte(user + ":" + pass))
+		hc.SetDefaultHeader(HeaderAuthorization, "Basic "+cred)
+	}
 }
 
 // NewHTTPClient initializes and returns a new HTTPClient.
-func NewHTTPClient(config ClientConfig) *HTTPClient {
-	return &HTTPClient{
+func NewHTTPClient(config ClientConfig, opts ...Option) *HTTPClient {
+	defaults := make(map[string]string, len(config.DefaultHeaders))
+	for key, value := range config.DefaultHeaders {
+		defaults[http.CanonicalHeaderKey(key)] = value
+	}
+
+	hc := &HTTPClient{
 		client: &fasthttp.Client{},
-		config: config,
+		streamClient: &fasthttp.Client{
+			StreamResponseBody:  true,
+			MaxResponseBodySize: streamBufferSize,
+		},
+		config:         config,
+		defaultHeaders: defaults,
+	}
+	if config.EnableCookies {
+		hc.jar = newCookieJar()
+	}
+	if config.EnableLogging {
+		hc.reqLogger = newRequestLogger(config.Logger, config.LogOptions)
+	}
+	for _, opt := range opts {
+		opt(hc)
+	}
+	return hc
+}
+
+// SetDefaultHeader sets a header sent with every subsequent request.
+// It is safe to call while requests are in flight.
+func (hc *HTTPClient) SetDefaultHeader(key, value string) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	next := make(map[string]string, len(hc.defaultHeaders)+1)
+	for k, v := range hc.defaultHeaders {
+		next[k] = v
+	}
+	next[http.CanonicalHeaderKey(key)] = value
+	hc.defaultHeaders = next
+}
+
+func (hc *HTTPClient) loadDefaultHeaders() map[string]string {
+	hc.mu.RLock()
+	defer hc.mu.RUnlock()
+	return hc.defaultHeaders
+}
+
+// requestOptions carries per-call settings through doRequest.
+type requestOptions struct {
+	// stream leaves the response body unread in resp.BodyStream().
+	stream bool
+	// timeout overrides ClientConfig.Timeout when positive.
+	timeout time.Duration
+	// errorOnNon2xx overrides ClientConfig.ErrorOnNon2xx when set.
+	errorOnNon2xx *bool
+	// baseURL overrides ClientConfig.BaseURL when set.
+	baseURL string
+}
+
+// legacyRequestOptions keeps Get, Post and Delete returning the body for
+// every status.
+func legacyRequestOptions() requestOptions {
+	disabled := false
+	return requestOptions{errorOnNon2xx: &disabled}
+}
+
+// RequestOption customizes a single request.
+type RequestOption func(*requestOptions)
+
+// WithTimeout overrides ClientConfig.Timeout for one request.
+func WithTimeout(timeout time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.timeout = timeout
 	}
 }
 
+// WithErrorOnNon2xx overrides ClientConfig.ErrorOnNon2xx for one request.
+func WithErrorOnNon2xx(enabled bool) RequestOption {
+	return func(o *requestOptions) {
+		o.errorOnNon2xx = &enabled
+	}
+}
+
+// WithBaseURL sends one request to baseURL instead of ClientConfig.BaseURL.
+// fasthttp keeps a separate connection pool per host, so this is cheaper
+// than a client per host.
+func WithBaseURL(baseURL string) RequestOption {
+	return func(o *requestOptions) {
+		o.baseURL = baseURL
+	}
+}
+
+func newRequestOptions(opts []RequestOption) requestOptions {
+	var o requestOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// doRequest applies default and per-request headers, runs the configured
+// interceptors and executes req.
+func (hc *HTTPClient) doRequest(ctx context.Context, req *fasthttp.Request, resp *fasthttp.Response, headers map[string]string, opts requestOptions) (*Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	info := hc.newRequestInfo(req, headers)
+	for _, intercept := range hc.config.RequestInterceptors {
+		if err := intercept(info); err != nil {
+			return nil, fmt.Errorf("request interceptor: %w", err)
+		}
+	}
+	applyRequestInfo(req, info)
+	hc.attachCookies(req, info.URL)
+	if hc.config.EnableCompression {
+		setAcceptEncoding(req)
+	}
+
+	client := hc.client
+	if opts.stream {
+		client = hc.streamClient
+	}
+
+	if hc.config.Metrics != nil {
+		hc.config.Metrics.IncInFlight()
+	}
+	host := string(req.URI().Host())
+	start := time.Now()
+	err := hc.send(ctx, client, req, resp, opts)
+	if hc.config.Metrics != nil {
+		hc.config.Metrics.DecInFlight()
+	}
+	if err != nil {
+		hc.logRequest(info, nil, time.Since(start), err)
+		hc.observeRequest(info, host, nil, time.Since(start), err)
+		return nil, err
+	}
+
+	response := newResponse(resp, !opts.stream)
+	if hc.config.EnableCompression {
+		if err := decompressResponse(resp, response, !opts.stream); err != nil {
+			return nil, err
+		}
+	}
+	hc.logRequest(info, response, time.Since(start), nil)
+	hc.observeRequest(info, host, response, time.Since(start), nil)
+	for _, intercept := range hc.config.ResponseInterceptors {
+		if err := intercept(info, response); err != nil {
+			return response, fmt.Errorf("response interceptor: %w", err)
+		}
+	}
+
+	if hc.errorOnNon2xx(opts) && response.StatusCode >= http.StatusBadRequest {
+		if opts.stream {
+			return response, readHTTPError(resp, hc.config.EnableCompression)
+		}
+		return response, newHTTPError(response.StatusCode, response.Body)
+	}
+
+	return response, nil
+}
+
+func (hc *HTTPClient) errorOnNon2xx(opts requestOptions) bool {
+	if opts.errorOnNon2xx != nil {
+		return *opts.errorOnNon2xx
+	}
+	return hc.config.ErrorOnNon2xx
+}
+
+// timeout returns the per-request or configured timeout, shortened to the
+// ctx deadline.
+func (hc *HTTPClient) timeout(ctx context.Context, override time.Duration) time.Duration {
+	timeout := hc.config.Timeout
+	if override > 0 {
+		timeout = override
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline); timeout <= 0 || remaining < timeout {
+			timeout = remaining
+		}
+	}
+	return timeout
+}
+
+func (hc *HTTPClient) logRequest(info *RequestInfo, resp *Response, duration time.Duration, err error) {
+	if hc.reqLogger != nil {
+		hc.reqLogger.logRequest(info, resp, duration, err)
+	}
+}
+
+func (hc *HTTPClient) observeRequest(info *RequestInfo, host string, resp *Response, duration time.Duration, err error) {
+	if hc.config.Metrics != nil {
+		hc.config.Metrics.ObserveRequest(info.Method, host, metricsStatus(resp, err), duration)
+	}
+}
+
+func (hc *HTTPClient) newRequestInfo(req *fasthttp.Request, headers map[string]string) *RequestInfo {
+	defaults := hc.loadDefaultHeaders()
+	merged := make(map[string]string, len(defaults)+len(headers))
+	for key, value := range defaults {
+		merged[key] = value
+	}
+	// Keys are canonicalised so "authorization" overrides a default
+	// "Authorization" instead of both being set in map order.
+	for key, value := range headers {
+		merged[http.CanonicalHeaderKey(key)] = value
+	}
+
+	// Streamed bodies are never read here; Body stays nil for them.
+	var body []byte
+	if !req.IsBodyStream() && len(req.Body()) > 0 {
+		body = append([]byte(nil), req.Body()...)
+	}
+
+	return &RequestInfo{
+		Method:  string(req.Header.Method()),
+		URL:     req.URI().String(),
+		Headers: merged,
+		Body:    body,
+		Attempt: 1,
+	}
+}
+
+// applyRequestInfo copies interceptor changes back onto req.
+func applyRequestInfo(req *fasthttp.Request, info *RequestInfo) {
+	req.Header.SetMethod(info.Method)
+	req.SetRequestURI(info.URL)
+	for key, value := range info.Headers {
+		req.Header.Set(key, value)
+	}
+	if req.IsBodyStream() {
+		return
+	}
+	if len(info.Body) > 0 || len(req.Body()) > 0 {
+		req.SetBody(info.Body)
+	}
+}
+
+// newResponse copies resp. The body is only read when withBody is set, so
+// streamed bodies are left untouched.
+func newResponse(resp *fasthttp.Response, withBody bool) *Response {
+	headers := make(map[string]string)
+	resp.Header.VisitAll(func(key, value []byte) {
+		headers[string(key)] = string(value)
+	})
+
+	response := &Response{
+		StatusCode: resp.StatusCode(),
+		Headers:    headers,
+	}
+	if withBody {
+		response.Body = append([]byte(nil), resp.Body()...)
+	}
+	return response
+}
+
 // Get sends a GET request to the specified endpoint with optional query parameters.
 func (hc *HTTPClient) Get(endpoint string, queryParams map[string]string, headers map[string]string) ([]byte, error) {
-	url := hc.config.BaseURL + endpoint
+	url := JoinURL(hc.config.BaseURL, endpoint)
 	req := fasthttp.AcquireRequest()
 	defer fasthttp.ReleaseRequest(req)
 
@@ -42,25 +361,21 @@ func (hc *HTTPClient) Get(endpoint string, queryParams map[string]string, header
 	}
 
 	req.Header.SetMethod(fasthttp.MethodGet)
-	for key, value := range headers {
-		req.Header.Set(key, value)
-	}
 
 	resp := fasthttp.AcquireResponse()
 	defer fasthttp.ReleaseResponse(resp)
 
-	// Set timeout
-	err := hc.client.DoTimeout(req, resp, hc.config.Timeout)
+	response, err := hc.doRequest(context.Background(), req, resp, headers, legacyRequestOptions())
 	if err != nil {
 		return nil, fmt.Errorf("failed to make GET request: %w", err)
 	}
 
-	return resp.Body(), nil
+	return response.Body, nil
 }
 
 // Post sends a POST request with a JSON payload.
 func (hc *HTTPClient) Post(endpoint string, body interface{}, headers map[string]string) ([]byte, error) {
-	url := hc.config.BaseURL + endpoint
+	url := JoinURL(hc.config.BaseURL, endpoint)
 	req := fasthttp.AcquireRequest()
 	defer fasthttp.ReleaseRequest(req)
 
@@ -73,45 +388,35 @@ func (hc *HTTPClient) Post(endpoint string, body interface{}, headers map[string
 	req.SetRequestURI(url)
 	req.Header.SetMethod(fasthttp.MethodPost)
 	req.Header.SetContentType(hc.config.ContentType)
-
-	for key, value := range headers {
-		req.Header.Set(key, value)
-	}
-
 	req.SetBody(bodyData)
 
 	resp := fasthttp.AcquireResponse()
 	defer fasthttp.ReleaseResponse(resp)
 
-	// Set timeout
-	err = hc.client.DoTimeout(req, resp, hc.config.Timeout)
+	response, err := hc.doRequest(context.Background(), req, resp, headers, legacyRequestOptions())
 	if err != nil {
 		return nil, fmt.Errorf("failed to make POST request: %w", err)
 	}
 
-	return resp.Body(), nil
+	return response.Body, nil
 }
 
 // Delete sends a DELETE request to the specified endpoint.
 func (hc *HTTPClient) Delete(endpoint string, headers map[string]string) ([]byte, error) {
-	url := hc.config.BaseURL + endpoint
+	url := JoinURL(hc.config.BaseURL, endpoint)
 	req := fasthttp.AcquireRequest()
 	defer fasthttp.ReleaseRequest(req)
 
 	req.SetRequestURI(url)
 	req.Header.SetMethod(fasthttp.MethodDelete)
-	for key, value := range headers {
-		req.Header.Set(key, value)
-	}
 
 	resp := fasthttp.AcquireResponse()
 	defer fasthttp.ReleaseResponse(resp)
 
-	// Set timeout
-	err := hc.client.DoTimeout(req, resp, hc.config.Timeout)
+	response, err := hc.doRequest(context.Background(), req, resp, headers, legacyRequestOptions())
 	if err != nil {
 		return nil, fmt.Errorf("failed to make DELETE request: %w", err)
 	}
 
-	return resp.Body(), nil
+	return response.Body, nil
 }
@@ -0,0 +1,225 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newHeaderServer echoes the X-Api-Key and Authorization request headers.
+func newHeaderServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s|%s|%d", r.Header.Get("X-Api-Key"), r.Header.Get("Authorization"), len(r.Header.Values("Authorization")))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestDefaultHeaderPrecedence(t *testing.T) {
+	basic := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:pass"))
+
+	tests := []struct {
+		name     string
+		defaults map[string]string
+		opts     []Option
+		set      map[string]string
+		headers  map[string]string
+		want     string
+	}{
+		{
+			name:     "config defaults",
+			defaults: map[string]string{"x-api-key": "k1", "Authorization": "Bearer cfg"},
+			want:     "k1|Bearer cfg|1",
+		},
+		{
+			name:     "option overrides config",
+			defaults: map[string]string{"authorization": "Bearer cfg"},
+			opts:     []Option{WithBearerToken("opt")},
+			want:     "|Bearer opt|1",
+		},
+		{
+			name: "basic auth",
+			opts: []Option{WithBasicAuth("user", "pass")},
+			want: "|" + basic + "|1",
+		},
+		{
+			name: "SetDefaultHeader overrides option",
+			opts: []Option{WithBearerToken("opt")},
+			set:  map[string]string{"AUTHORIZATION": "Bearer set"},
+			want: "|Bearer set|1",
+		},
+		{
+			name:     "request header overrides default",
+			defaults: map[string]string{"X-Api-Key": "k1"},
+			opts:     []Option{WithBearerToken("opt")},
+			headers:  map[string]string{"authorization": "Bearer req", "x-api-key": "k2"},
+			want:     "k2|Bearer req|1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newHeaderServer(t)
+			hc := NewHTTPClient(ClientConfig{BaseURL: srv.URL, Timeout: 5 * time.Second, DefaultHeaders: tt.defaults}, tt.opts...)
+			for key, value := range tt.set {
+				hc.SetDefaultHeader(key, value)
+			}
+
+			// Repeat so a map-order dependent merge would show up.
+			for i := 0; i < 20; i++ {
+				resp, err := hc.GetWithQuery(context.Background(), "/", nil, tt.headers)
+				if err != nil {
+					t.Fatalf("GetWithQuery() error = %v", err)
+				}
+				if got := string(resp.Body); got != tt.want {
+					t.Fatalf("headers = %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultHeadersNotShared(t *testing.T) {
+	defaults := map[string]string{"X-Api-Key": "k1"}
+	hc := NewHTTPClient(ClientConfig{DefaultHeaders: defaults})
+	hc.SetDefaultHeader("X-Api-Key", "k2")
+	if defaults["X-Api-Key"] != "k1" {
+		t.Errorf("config map was modified: %v", defaults)
+	}
+}
+
+// Run with -race: SetDefaultHeader while requests are in flight.
+func TestSetDefaultHeaderConcurrent(t *testing.T) {
+	srv := newHeaderServer(t)
+	hc := NewHTTPClient(ClientConfig{BaseURL: srv.URL, Timeout: 5 * time.Second})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				hc.SetDefaultHeader("X-Api-Key", fmt.Sprintf("k%d-%d", i, j))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if _, err := hc.GetWithQuery(context.Background(), "/", nil, map[string]string{"X-Request": "1"}); err != nil {
+					t.Errorf("GetWithQuery() error = %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestInterceptors(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		fmt.Fprintf(w, "%s %s %s", r.Method, r.URL.Path, r.Header.Get("X-Trace"))
+	}))
+	t.Cleanup(srv.Close)
+
+	var order []string
+	hc := NewHTTPClient(ClientConfig{
+		BaseURL: srv.URL,
+		Timeout: 5 * time.Second,
+		RequestInterceptors: []func(*RequestInfo) error{
+			func(req *RequestInfo) error {
+				order = append(order, "req1")
+				req.Headers["X-Trace"] = "first"
+				req.URL += "/rewritten"
+				return nil
+			},
+			func(req *RequestInfo) error {
+				order = append(order, "req2:"+req.Headers["X-Trace"])
+				req.Headers["X-Trace"] += ",second"
+				return nil
+			},
+		},
+		ResponseInterceptors: []func(*RequestInfo, *Response) error{
+			func(req *RequestInfo, resp *Response) error {
+				order = append(order, "resp1:"+string(resp.Body))
+				resp.Headers["X-Seen"] = "resp1"
+				return nil
+			},
+			func(req *RequestInfo, resp *Response) error {
+				order = append(order, "resp2:"+resp.Headers["X-Seen"])
+				return nil
+			},
+		},
+	})
+
+	resp, err := hc.GetWithQuery(context.Background(), "/orders", nil, nil)
+	if err != nil {
+		t.Fatalf("GetWithQuery() error = %v", err)
+	}
+	want := []string{
+		"req1",
+		"req2:first",
+		"resp1:GET /orders/rewritten first,second",
+		"resp2:resp1",
+	}
+	if !slices.Equal(order, want) {
+		t.Errorf("interceptor calls = %q, want %q", order, want)
+	}
+	if resp.Headers["X-Seen"] != "resp1" {
+		t.Errorf("response header set by interceptor = %q, want resp1", resp.Headers["X-Seen"])
+	}
+
+	t.Run("request interceptor aborts", func(t *testing.T) {
+		hits.Store(0)
+		errDenied := errors.New("denied")
+		var later bool
+		hc := NewHTTPClient(ClientConfig{
+			BaseURL: srv.URL,
+			Timeout: 5 * time.Second,
+			RequestInterceptors: []func(*RequestInfo) error{
+				func(*RequestInfo) error { return errDenied },
+				func(*RequestInfo) error { later = true; return nil },
+			},
+		})
+
+		resp, err := hc.GetWithQuery(context.Background(), "/", nil, nil)
+		if !errors.Is(err, errDenied) {
+			t.Fatalf("GetWithQuery() error = %v, want errDenied", err)
+		}
+		if resp != nil {
+			t.Errorf("GetWithQuery() response = %+v, want nil", resp)
+		}
+		if later {
+			t.Error("interceptor after the failing one ran")
+		}
+		if n := hits.Load(); n != 0 {
+			t.Errorf("server hit %d times, want 0", n)
+		}
+	})
+
+	t.Run("response interceptor fails", func(t *testing.T) {
+		errRejected := errors.New("rejected")
+		hc := NewHTTPClient(ClientConfig{
+			BaseURL: srv.URL,
+			Timeout: 5 * time.Second,
+			ResponseInterceptors: []func(*RequestInfo, *Response) error{
+				func(*RequestInfo, *Response) error { return errRejected },
+			},
+		})
+
+		_, err := hc.NewRequest(http.MethodGet, "/").Do(context.Background())
+		if !errors.Is(err, errRejected) {
+			t.Fatalf("Do() error = %v, want errRejected", err)
+		}
+	})
+}
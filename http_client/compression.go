@@ -0,0 +1,87 @@
+package httpclient
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/valyala/fasthttp"
+)
+
+const acceptedEncodings = "gzip, deflate, br"
+
+// setAcceptEncoding offers compressed responses unless the caller already
+// chose an Accept-Encoding.
+func setAcceptEncoding(req *fasthttp.Request) {
+	if len(req.Header.Peek(fasthttp.HeaderAcceptEncoding)) == 0 {
+		req.Header.Set(fasthttp.HeaderAcceptEncoding, acceptedEncodings)
+	}
+}
+
+// decompressResponse replaces response.Body with the decoded body of resp
+// and drops the headers describing the encoded form. Streamed bodies are
+// decoded later by decodingReader.
+func decompressResponse(resp *fasthttp.Response, response *Response, withBody bool) error {
+	encoding := contentEncoding(resp)
+	if encoding == "" {
+		return nil
+	}
+
+	if withBody {
+		var (
+			body []byte
+			err  error
+		)
+		switch encoding {
+		case "gzip":
+			body, err = resp.BodyGunzip()
+		case "deflate":
+			body, err = resp.BodyInflate()
+		case "br":
+			body, err = resp.BodyUnbrotli()
+		default:
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to decode %s response body: %w", encoding, err)
+		}
+		response.Body = body
+	}
+
+	stripEncodingHeaders(response.Headers)
+	return nil
+}
+
+// decodingReader wraps a streamed body so it is decoded on the fly.
+func decodingReader(encoding string, body io.Reader) (io.ReadCloser, error) {
+	switch encoding {
+	case "gzip":
+		return gzip.NewReader(body)
+	case "deflate":
+		return zlib.NewReader(body)
+	case "br":
+		return io.NopCloser(brotli.NewReader(body)), nil
+	default:
+		return io.NopCloser(body), nil
+	}
+}
+
+func contentEncoding(resp *fasthttp.Response) string {
+	encoding := strings.ToLower(strings.TrimSpace(string(resp.Header.ContentEncoding())))
+	if encoding == "identity" {
+		return ""
+	}
+	return encoding
+}
+
+func stripEncodingHeaders(headers map[string]string) {
+	for key := range headers {
+		switch strings.ToLower(key) {
+		case "content-encoding", "content-length":
+			delete(headers, key)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package httpclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+)
+
+func compress(t *testing.T, encoding string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "br":
+		w = brotli.NewWriter(&buf)
+	default:
+		t.Fatalf("unsupported encoding %q", encoding)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("compress: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("compress: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// incompressible returns n bytes of base64 text that compresses poorly, so
+// the encoded body stays larger than streamBufferSize.
+func incompressible(n int) []byte {
+	raw := make([]byte, n)
+	rand.New(rand.NewSource(1)).Read(raw)
+	return []byte(base64.StdEncoding.EncodeToString(raw))[:n]
+}
+
+// newEncodedServer answers every request with status and plain encoded with
+// the encoding named by the request path, such as /gzip.
+func newEncodedServer(t *testing.T, status int, plain []byte) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := strings.TrimPrefix(r.URL.Path, "/")
+		w.Header().Set("Content-Encoding", encoding)
+		w.WriteHeader(status)
+		w.Write(compress(t, encoding, plain))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCompressedRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		plain []byte
+	}{
+		{"small", []byte(`{"message":"hello"}`)},
+		{"larger than stream buffer", incompressible(3 * streamBufferSize)},
+	}
+
+	for _, tt := range tests {
+		srv := newEncodedServer(t, http.StatusOK, tt.plain)
+		hc := NewHTTPClient(ClientConfig{BaseURL: srv.URL, Timeout: 5 * time.Second, EnableCompression: true})
+
+		for _, encoding := range []string{"gzip", "br"} {
+			t.Run(tt.name+"/"+encoding, func(t *testing.T) {
+				resp, err := hc.GetWithQuery(context.Background(), "/"+encoding, nil, nil)
+				if err != nil {
+					t.Fatalf("GetWithQuery() error = %v", err)
+				}
+				if !bytes.Equal(resp.Body, tt.plain) {
+					t.Errorf("buffered body has %d bytes, want the %d plain bytes", len(resp.Body), len(tt.plain))
+				}
+
+				var buf bytes.Buffer
+				if _, err := hc.GetStream(context.Background(), "/"+encoding, nil, nil, &buf); err != nil {
+					t.Fatalf("GetStream() error = %v", err)
+				}
+				if !bytes.Equal(buf.Bytes(), tt.plain) {
+					t.Errorf("streamed body has %d bytes, want the %d plain bytes", buf.Len(), len(tt.plain))
+				}
+			})
+		}
+	}
+}
+
+func TestCompressedHTTPErrorBody(t *testing.T) {
+	tests := []struct {
+		name  string
+		plain []byte
+		want  []byte
+	}{
+		{"small", []byte(`{"error":"boom"}`), []byte(`{"error":"boom"}`)},
+		{"larger than cap", incompressible(3 * streamBufferSize), incompressible(3 * streamBufferSize)[:maxErrorBodyBytes]},
+	}
+
+	for _, tt := range tests {
+		srv := newEncodedServer(t, http.StatusInternalServerError, tt.plain)
+		hc := NewHTTPClient(ClientConfig{
+			BaseURL:           srv.URL,
+			Timeout:           5 * time.Second,
+			EnableCompression: true,
+			ErrorOnNon2xx:     true,
+		})
+
+		for _, encoding := range []string{"gzip", "br"} {
+			t.Run(tt.name+"/"+encoding, func(t *testing.T) {
+				_, err := hc.GetWithQuery(context.Background(), "/"+encoding, nil, nil)
+				httpErr, ok := AsHTTPError(err)
+				if !ok {
+					t.Fatalf("GetWithQuery() error = %v, want *HTTPError", err)
+				}
+				if !bytes.Equal(httpErr.Body, tt.want) {
+					t.Errorf("buffered HTTPError.Body has %d bytes, want %d decoded bytes", len(httpErr.Body), len(tt.want))
+				}
+
+				var buf bytes.Buffer
+				_, err = hc.GetStream(context.Background(), "/"+encoding, nil, nil, &buf)
+				httpErr, ok = AsHTTPError(err)
+				if !ok {
+					t.Fatalf("GetStream() error = %v, want *HTTPError", err)
+				}
+				if !bytes.Equal(httpErr.Body, tt.want) {
+					t.Errorf("streamed HTTPError.Body has %d bytes, want %d decoded bytes", len(httpErr.Body), len(tt.want))
+				}
+				if buf.Len() != 0 {
+					t.Errorf("GetStream() wrote %d bytes of the error body to w", buf.Len())
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+
+	"github.com/valyala/fasthttp"
+)
+
+// ErrCookiesDisabled is returned by SetCookie when ClientConfig.EnableCookies
+// is false.
+var ErrCookiesDisabled = errors.New("cookies are not enabled on this client")
+
+// newCookieJar returns a net/http/cookiejar, which handles domain and path
+// matching, expiry and the Secure attribute and is safe for concurrent use.
+func newCookieJar() *cookiejar.Jar {
+	// cookiejar.New only fails for invalid options.
+	jar, _ := cookiejar.New(nil)
+	return jar
+}
+
+func (hc *HTTPClient) cookieJar() *cookiejar.Jar {
+	hc.mu.RLock()
+	defer hc.mu.RUnlock()
+	return hc.jar
+}
+
+// SetCookie stores cookie for rawURL so it is sent on matching requests.
+func (hc *HTTPClient) SetCookie(rawURL string, cookie *http.Cookie) error {
+	jar := hc.cookieJar()
+	if jar == nil {
+		return ErrCookiesDisabled
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid cookie url: %w", err)
+	}
+	jar.SetCookies(u, []*http.Cookie{cookie})
+	return nil
+}
+
+// Cookies returns the unexpired cookies that would be sent to rawURL.
+func (hc *HTTPClient) Cookies(rawURL string) []*http.Cookie {
+	jar := hc.cookieJar()
+	if jar == nil {
+		return nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+	return jar.Cookies(u)
+}
+
+// ClearCookies removes every stored cookie.
+func (hc *HTTPClient) ClearCookies() {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	if hc.jar != nil {
+		hc.jar = newCookieJar()
+	}
+}
+
+// attachCookies adds stored cookies matching rawURL to req.
+func (hc *HTTPClient) attachCookies(req *fasthttp.Request, rawURL string) {
+	for _, cookie := range hc.Cookies(rawURL) {
+		req.Header.SetCookie(cookie.Name, cookie.Value)
+	}
+}
+
+// storeCookies saves the Set-Cookie headers of resp for rawURL.
+func (hc *HTTPClient) storeCookies(resp *fasthttp.Response, rawURL string) {
+	jar := hc.cookieJar()
+	if jar == nil {
+		return
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return
+	}
+
+	var cookies []*http.Cookie
+	resp.Header.VisitAllCookie(func(_, value []byte) {
+		if cookie, err := http.ParseSetCookie(string(value)); err == nil {
+			cookies = append(cookies, cookie)
+		}
+	})
+	if len(cookies) > 0 {
+		jar.SetCookies(u, cookies)
+	}
+}
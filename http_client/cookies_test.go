@@ -0,0 +1,96 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newCookieServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+		http.SetCookie(w, &http.Cookie{Name: "scoped", Value: "api", Path: "/api"})
+	})
+	mux.HandleFunc("/logout", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Path: "/", MaxAge: -1})
+	})
+	echo := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("Cookie")))
+	}
+	mux.HandleFunc("/me", echo)
+	mux.HandleFunc("/api/me", echo)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func getBody(t *testing.T, hc *HTTPClient, endpoint string) string {
+	t.Helper()
+	resp, err := hc.GetWithQuery(context.Background(), endpoint, nil, nil)
+	if err != nil {
+		t.Fatalf("GetWithQuery(%s) error = %v", endpoint, err)
+	}
+	return string(resp.Body)
+}
+
+func TestCookies(t *testing.T) {
+	srv := newCookieServer(t)
+	hc := NewHTTPClient(ClientConfig{BaseURL: srv.URL, Timeout: 5 * time.Second, EnableCookies: true})
+
+	getBody(t, hc, "/login")
+	if got := getBody(t, hc, "/me"); got != "session=abc" {
+		t.Errorf("cookies sent to /me = %q, want session=abc", got)
+	}
+	if got := getBody(t, hc, "/api/me"); got != "scoped=api; session=abc" {
+		t.Errorf("cookies sent to /api/me = %q, want scoped=api; session=abc", got)
+	}
+
+	getBody(t, hc, "/logout")
+	if got := getBody(t, hc, "/me"); got != "" {
+		t.Errorf("cookies sent after logout = %q, want none", got)
+	}
+	if cookies := hc.Cookies(srv.URL + "/api/me"); len(cookies) != 1 || cookies[0].Name != "scoped" {
+		t.Errorf("Cookies() after logout = %v, want only scoped", cookies)
+	}
+
+	hc.ClearCookies()
+	if got := getBody(t, hc, "/api/me"); got != "" {
+		t.Errorf("cookies sent after ClearCookies = %q, want none", got)
+	}
+}
+
+func TestSetCookie(t *testing.T) {
+	srv := newCookieServer(t)
+	hc := NewHTTPClient(ClientConfig{BaseURL: srv.URL, Timeout: 5 * time.Second, EnableCookies: true})
+
+	if err := hc.SetCookie(srv.URL, &http.Cookie{Name: "token", Value: "t1", Path: "/"}); err != nil {
+		t.Fatalf("SetCookie() error = %v", err)
+	}
+	if err := hc.SetCookie(srv.URL, &http.Cookie{Name: "old", Value: "x", Path: "/", Expires: time.Now().Add(-time.Hour)}); err != nil {
+		t.Fatalf("SetCookie() error = %v", err)
+	}
+	if got := getBody(t, hc, "/me"); got != "token=t1" {
+		t.Errorf("cookies sent = %q, want token=t1", got)
+	}
+}
+
+func TestCookiesDisabled(t *testing.T) {
+	srv := newCookieServer(t)
+	hc := NewHTTPClient(ClientConfig{BaseURL: srv.URL, Timeout: 5 * time.Second})
+
+	getBody(t, hc, "/login")
+	if got := getBody(t, hc, "/me"); got != "" {
+		t.Errorf("cookies sent with cookies disabled = %q, want none", got)
+	}
+	if err := hc.SetCookie(srv.URL, &http.Cookie{Name: "token", Value: "t1"}); !errors.Is(err, ErrCookiesDisabled) {
+		t.Errorf("SetCookie() error = %v, want ErrCookiesDisabled", err)
+	}
+	if cookies := hc.Cookies(srv.URL); cookies != nil {
+		t.Errorf("Cookies() = %v, want nil", cookies)
+	}
+}
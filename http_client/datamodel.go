@@ -6,3 +6,23 @@ type CommonResponse struct {
 }
 
 var ContentTypeJSON = "application/json"
+
+var HeaderAuthorization = "Authorization"
+
+// RequestInfo describes an outgoing request independently of fasthttp's
+// pooled request objects, so interceptors may keep or modify it freely.
+type RequestInfo struct {
+	Method  string
+	URL     string
+	Headers map[string]string
+	Body    []byte
+	// Attempt is the 1-based attempt number of this request.
+	Attempt int
+}
+
+// Response is a copy of an HTTP response that outlives the fasthttp request.
+type Response struct {
+	StatusCode int
+	Headers    map[string]string
+	Body       []byte
+}
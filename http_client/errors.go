@@ -0,0 +1,89 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/valyala/fasthttp"
+)
+
+// maxErrorBodyBytes caps the response body kept on an HTTPError.
+const maxErrorBodyBytes = 64 << 10
+
+// HTTPError is returned for responses with a status of 400 or above when
+// ErrorOnNon2xx is enabled.
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	// Body holds at most the first 64 KiB of the response body.
+	Body []byte
+}
+
+func newHTTPError(statusCode int, body []byte) *HTTPError {
+	if len(body) > maxErrorBodyBytes {
+		body = body[:maxErrorBodyBytes]
+	}
+	return &HTTPError{
+		StatusCode: statusCode,
+		Status:     http.StatusText(statusCode),
+		Body:       append([]byte(nil), body...),
+	}
+}
+
+// readHTTPError builds an HTTPError from a streamed response, decoding its
+// body first when decompress is set. A body that fails to decode is left
+// empty.
+func readHTTPError(resp *fasthttp.Response, decompress bool) *HTTPError {
+	var data []byte
+	if body, err := streamBody(resp, decompress); err == nil {
+		data, _ = io.ReadAll(io.LimitReader(body, maxErrorBodyBytes))
+		body.Close()
+	}
+	return newHTTPError(resp.StatusCode(), data)
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("unexpected status %d %s", e.StatusCode, e.Status)
+}
+
+// IsNotFound reports whether the error is a 404.
+func (e *HTTPError) IsNotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
+
+// IsServerError reports whether the error is a 5xx.
+func (e *HTTPError) IsServerError() bool {
+	return e.StatusCode >= http.StatusInternalServerError
+}
+
+// AsHTTPError finds the first HTTPError in err's chain.
+func AsHTTPError(err error) (*HTTPError, bool) {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr, true
+	}
+	return nil, false
+}
+
+// IsNotFound reports whether err wraps a 404 HTTPError.
+func IsNotFound(err error) bool {
+	httpErr, ok := AsHTTPError(err)
+	return ok && httpErr.IsNotFound()
+}
+
+// IsServerError reports whether err wraps a 5xx HTTPError.
+func IsServerError(err error) bool {
+	httpErr, ok := AsHTTPError(err)
+	return ok && httpErr.IsServerError()
+}
+
+// requestError wraps a doRequest failure for a Response-returning method.
+// An HTTPError is returned unwrapped together with its response.
+func requestError(method string, response *Response, err error) (*Response, error) {
+	if _, ok := err.(*HTTPError); ok {
+		return response, err
+	}
+	return nil, fmt.Errorf("failed to make %s request: %w", method, err)
+}
@@ -0,0 +1,121 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newStatusServer answers with the status named by the request path and
+// a body of "body" repeated to the size given in the "size" query parameter.
+func newStatusServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("size"))
+		w.WriteHeader(status)
+		w.Write(bytes.Repeat([]byte("b"), size))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestHTTPError(t *testing.T) {
+	srv := newStatusServer(t)
+	hc := NewHTTPClient(ClientConfig{BaseURL: srv.URL, Timeout: 5 * time.Second, ErrorOnNon2xx: true})
+
+	tests := []struct {
+		endpoint    string
+		status      int
+		bodyLen     int
+		notFound    bool
+		serverError bool
+	}{
+		{"/404?size=10", http.StatusNotFound, 10, true, false},
+		{"/400?size=0", http.StatusBadRequest, 0, false, false},
+		{"/500?size=200000", http.StatusInternalServerError, maxErrorBodyBytes, false, true},
+		{"/503?size=10", http.StatusServiceUnavailable, 10, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			resp, err := hc.GetWithQuery(context.Background(), tt.endpoint, nil, nil)
+			httpErr, ok := AsHTTPError(err)
+			if !ok {
+				t.Fatalf("GetWithQuery() error = %v, want *HTTPError", err)
+			}
+			if resp == nil || resp.StatusCode != tt.status {
+				t.Errorf("GetWithQuery() response = %+v, want status %d", resp, tt.status)
+			}
+			if httpErr.StatusCode != tt.status || httpErr.Status != http.StatusText(tt.status) {
+				t.Errorf("HTTPError = %d %q, want %d %q", httpErr.StatusCode, httpErr.Status, tt.status, http.StatusText(tt.status))
+			}
+			if len(httpErr.Body) != tt.bodyLen {
+				t.Errorf("len(HTTPError.Body) = %d, want %d", len(httpErr.Body), tt.bodyLen)
+			}
+			if httpErr.IsNotFound() != tt.notFound || IsNotFound(err) != tt.notFound {
+				t.Errorf("IsNotFound = %t, want %t", httpErr.IsNotFound(), tt.notFound)
+			}
+			if httpErr.IsServerError() != tt.serverError || IsServerError(err) != tt.serverError {
+				t.Errorf("IsServerError = %t, want %t", httpErr.IsServerError(), tt.serverError)
+			}
+		})
+	}
+
+	if _, err := hc.GetWithQuery(context.Background(), "/204", nil, nil); err != nil {
+		t.Errorf("GetWithQuery() for 204 error = %v", err)
+	}
+	if IsNotFound(errors.New("not found")) || IsServerError(nil) {
+		t.Error("IsNotFound/IsServerError matched an error without an HTTPError")
+	}
+}
+
+func TestHTTPErrorDisabled(t *testing.T) {
+	srv := newStatusServer(t)
+
+	t.Run("config disabled", func(t *testing.T) {
+		hc := NewHTTPClient(ClientConfig{BaseURL: srv.URL, Timeout: 5 * time.Second})
+		resp, err := hc.GetWithQuery(context.Background(), "/500?size=3", nil, nil)
+		if err != nil {
+			t.Fatalf("GetWithQuery() error = %v", err)
+		}
+		if resp.StatusCode != http.StatusInternalServerError || string(resp.Body) != "bbb" {
+			t.Errorf("GetWithQuery() = %d %q, want 500 \"bbb\"", resp.StatusCode, resp.Body)
+		}
+	})
+
+	t.Run("request override", func(t *testing.T) {
+		hc := NewHTTPClient(ClientConfig{BaseURL: srv.URL, Timeout: 5 * time.Second, ErrorOnNon2xx: true})
+		if _, err := hc.GetWithQuery(context.Background(), "/500", nil, nil, WithErrorOnNon2xx(false)); err != nil {
+			t.Errorf("GetWithQuery() with WithErrorOnNon2xx(false) error = %v", err)
+		}
+
+		hc = NewHTTPClient(ClientConfig{BaseURL: srv.URL, Timeout: 5 * time.Second})
+		if _, err := hc.GetWithQuery(context.Background(), "/500", nil, nil, WithErrorOnNon2xx(true)); !IsServerError(err) {
+			t.Errorf("GetWithQuery() with WithErrorOnNon2xx(true) error = %v, want a 5xx HTTPError", err)
+		}
+	})
+
+	t.Run("legacy methods", func(t *testing.T) {
+		hc := NewHTTPClient(ClientConfig{BaseURL: srv.URL, Timeout: 5 * time.Second, ErrorOnNon2xx: true})
+
+		body, err := hc.Get("/404", map[string]string{"size": "3"}, nil)
+		if err != nil || string(body) != "bbb" {
+			t.Errorf("Get() = %q, %v; want \"bbb\", nil", body, err)
+		}
+		body, err = hc.Post("/500?size=3", map[string]string{"id": "1"}, nil)
+		if err != nil || string(body) != "bbb" {
+			t.Errorf("Post() = %q, %v; want \"bbb\", nil", body, err)
+		}
+		body, err = hc.Delete("/500?size=3", nil)
+		if err != nil || string(body) != "bbb" {
+			t.Errorf("Delete() = %q, %v; want \"bbb\", nil", body, err)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package httpclient
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/11SF/go-common/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const redactedValue = "[REDACTED]"
+
+// LogOptions controls what the request logging hook records.
+type LogOptions struct {
+	LogRequestBody  bool
+	LogResponseBody bool
+	// MaxBodyLogBytes truncates logged bodies. Zero or less logs them in full.
+	MaxBodyLogBytes int
+	// RedactHeaders lists additional header names whose values are masked.
+	// Authorization is always masked.
+	RedactHeaders []string
+}
+
+type requestLogger struct {
+	log    *zap.Logger
+	opts   LogOptions
+	redact map[string]struct{}
+}
+
+func newRequestLogger(log *zap.Logger, opts LogOptions) *requestLogger {
+	if log == nil {
+		log = logger.CreateLogger(logger.Config{LogLevel: "debug"})
+	}
+
+	redact := map[string]struct{}{
+		http.CanonicalHeaderKey(HeaderAuthorization): {},
+	}
+	for _, name := range opts.RedactHeaders {
+		redact[http.CanonicalHeaderKey(name)] = struct{}{}
+	}
+
+	return &requestLogger{
+		log:    log,
+		opts:   opts,
+		redact: redact,
+	}
+}
+
+// logRequest emits one record per request: DEBUG for success, WARN for 4xx
+// and ERROR for 5xx or transport failures.
+func (rl *requestLogger) logRequest(req *RequestInfo, resp *Response, duration time.Duration, err error) {
+	fields := []zap.Field{
+		zap.String("method", req.Method),
+		zap.String("url", req.URL),
+		zap.Int("attempt", req.Attempt),
+		zap.Duration("duration", duration),
+		zap.Any("headers", rl.redactHeaders(req.Headers)),
+	}
+	if rl.opts.LogRequestBody && len(req.Body) > 0 {
+		fields = append(fields, zap.String("request_body", rl.truncate(req.Body)))
+	}
+
+	level := zapcore.DebugLevel
+	if resp != nil {
+		fields = append(fields, zap.Int("status", resp.StatusCode))
+		if rl.opts.LogResponseBody && len(resp.Body) > 0 {
+			fields = append(fields, zap.String("response_body", rl.truncate(resp.Body)))
+		}
+		switch {
+		case resp.StatusCode >= http.StatusInternalServerError:
+			level = zapcore.ErrorLevel
+		case resp.StatusCode >= http.StatusBadRequest:
+			level = zapcore.WarnLevel
+		}
+	}
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+		level = zapcore.ErrorLevel
+	}
+
+	if ce := rl.log.Check(level, "http client request"); ce != nil {
+		ce.Write(fields...)
+	}
+}
+
+func (rl *requestLogger) redactHeaders(headers map[string]string) map[string]string {
+	out := make(map[string]string, len(headers))
+	for key, value := range headers {
+		if _, ok := rl.redact[http.CanonicalHeaderKey(key)]; ok {
+			value = redactedValue
+		}
+		out[key] = value
+	}
+	return out
+}
+
+func (rl *requestLogger) truncate(body []byte) string {
+	limit := rl.opts.MaxBodyLogBytes
+	if limit <= 0 || len(body) <= limit {
+		return string(body)
+	}
+
+	var b strings.Builder
+	b.Write(body[:limit])
+	b.WriteString("...(truncated)")
+	return b.String()
+}
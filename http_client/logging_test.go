@@ -0,0 +1,125 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newObservedClient(t *testing.T, baseURL string, opts LogOptions) (*HTTPClient, *observer.ObservedLogs) {
+	t.Helper()
+	core, logs := observer.New(zapcore.DebugLevel)
+	hc := NewHTTPClient(ClientConfig{
+		BaseURL:       baseURL,
+		Timeout:       5 * time.Second,
+		EnableLogging: true,
+		LogOptions:    opts,
+		Logger:        zap.New(core),
+	})
+	return hc, logs
+}
+
+func TestRequestLogging(t *testing.T) {
+	// The path names the status to answer with.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		w.WriteHeader(status)
+		w.Write([]byte("response body"))
+	}))
+	t.Cleanup(srv.Close)
+
+	hc, logs := newObservedClient(t, srv.URL, LogOptions{
+		LogRequestBody:  true,
+		LogResponseBody: true,
+		MaxBodyLogBytes: 8,
+		RedactHeaders:   []string{"x-api-key"},
+	})
+
+	for _, status := range []int{http.StatusOK, http.StatusNotFound, http.StatusInternalServerError} {
+		_, err := hc.NewRequest(http.MethodPost, "/"+strconv.Itoa(status)).
+			Header("authorization", "Bearer secret").
+			Header("X-Api-Key", "key").
+			Header("X-Request-Id", "req-1").
+			Body([]byte("request body"), "text/plain").
+			Do(context.Background())
+		if err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+	}
+
+	entries := logs.AllUntimed()
+	wantLevels := []zapcore.Level{zapcore.DebugLevel, zapcore.WarnLevel, zapcore.ErrorLevel}
+	if len(entries) != len(wantLevels) {
+		t.Fatalf("got %d log entries, want %d", len(entries), len(wantLevels))
+	}
+	for i, entry := range entries {
+		if entry.Level != wantLevels[i] {
+			t.Errorf("entry %d level = %s, want %s", i, entry.Level, wantLevels[i])
+		}
+
+		fields := entry.ContextMap()
+		headers, _ := fields["headers"].(map[string]string)
+		wantHeaders := map[string]string{
+			"Authorization": redactedValue,
+			"X-Api-Key":     redactedValue,
+			"X-Request-Id":  "req-1",
+		}
+		for key, want := range wantHeaders {
+			if headers[key] != want {
+				t.Errorf("entry %d header %s = %q, want %q", i, key, headers[key], want)
+			}
+		}
+		if got := fields["request_body"]; got != "request ...(truncated)" {
+			t.Errorf("entry %d request_body = %q, want truncated to 8 bytes", i, got)
+		}
+		if got := fields["response_body"]; got != "response...(truncated)" {
+			t.Errorf("entry %d response_body = %q, want truncated to 8 bytes", i, got)
+		}
+	}
+}
+
+func TestRequestLoggingDefaults(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("response body"))
+	}))
+	t.Cleanup(srv.Close)
+
+	hc, logs := newObservedClient(t, srv.URL, LogOptions{})
+	_, err := hc.NewRequest(http.MethodPost, "/").Body([]byte("request body"), "text/plain").Do(context.Background())
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+
+	fields := logs.AllUntimed()[0].ContextMap()
+	for _, key := range []string{"request_body", "response_body"} {
+		if _, ok := fields[key]; ok {
+			t.Errorf("%s logged without being enabled", key)
+		}
+	}
+}
+
+func TestRequestLoggingTransportError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	hc, logs := newObservedClient(t, srv.URL, LogOptions{})
+	if _, err := hc.GetWithQuery(context.Background(), "/", nil, nil); err == nil {
+		t.Fatal("GetWithQuery() to a closed server succeeded")
+	}
+
+	entries := logs.FilterLevelExact(zapcore.ErrorLevel).AllUntimed()
+	if len(entries) != 1 {
+		t.Fatalf("got %d ERROR entries, want 1", len(entries))
+	}
+	if _, ok := entries[0].ContextMap()["error"]; !ok {
+		t.Error("ERROR entry has no error field")
+	}
+}
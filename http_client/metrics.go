@@ -0,0 +1,27 @@
+package httpclient
+
+import (
+	"strconv"
+	"time"
+)
+
+// statusError is the status label reported for requests that failed before
+// a response was received.
+const statusError = "error"
+
+// MetricsRecorder receives request metrics from an HTTPClient.
+// Implementations must be safe for concurrent use.
+type MetricsRecorder interface {
+	// ObserveRequest records one completed request. status is the HTTP
+	// status code, or "error" for transport failures.
+	ObserveRequest(method, host, status string, d time.Duration)
+	IncInFlight()
+	DecInFlight()
+}
+
+func metricsStatus(resp *Response, err error) string {
+	if err != nil || resp == nil {
+		return statusError
+	}
+	return strconv.Itoa(resp.StatusCode)
+}
@@ -0,0 +1,124 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+type observation struct {
+	method, host, status string
+}
+
+type fakeRecorder struct {
+	mu           sync.Mutex
+	observations []observation
+	inFlight     int
+}
+
+func (r *fakeRecorder) ObserveRequest(method, host, status string, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.observations = append(r.observations, observation{method, host, status})
+}
+
+func (r *fakeRecorder) IncInFlight() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inFlight++
+}
+
+func (r *fakeRecorder) DecInFlight() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inFlight--
+}
+
+func (r *fakeRecorder) snapshot() ([]observation, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.observations), r.inFlight
+}
+
+func TestMetrics(t *testing.T) {
+	srv := newStatusServer(t)
+	host := mustHost(t, srv.URL)
+	rec := &fakeRecorder{}
+	hc := NewHTTPClient(ClientConfig{BaseURL: srv.URL, Timeout: 5 * time.Second, Metrics: rec})
+
+	if _, err := hc.GetWithQuery(context.Background(), "/200", nil, nil); err != nil {
+		t.Fatalf("GetWithQuery() error = %v", err)
+	}
+	if _, err := hc.NewRequest(http.MethodPost, "/500").Do(context.Background()); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	if _, err := hc.GetWithQuery(context.Background(), "/", nil, nil, WithBaseURL(closed.URL)); err == nil {
+		t.Fatal("GetWithQuery() to a closed server succeeded")
+	}
+
+	observations, inFlight := rec.snapshot()
+	want := []observation{
+		{http.MethodGet, host, "200"},
+		{http.MethodPost, host, "500"},
+		{http.MethodGet, mustHost(t, closed.URL), statusError},
+	}
+	if !slices.Equal(observations, want) {
+		t.Errorf("observations = %v, want %v", observations, want)
+	}
+	if inFlight != 0 {
+		t.Errorf("in-flight after all requests = %d, want 0", inFlight)
+	}
+}
+
+func TestMetricsInFlight(t *testing.T) {
+	received := make(chan struct{})
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+		<-release
+	}))
+	t.Cleanup(srv.Close)
+	rec := &fakeRecorder{}
+	hc := NewHTTPClient(ClientConfig{BaseURL: srv.URL, Timeout: 5 * time.Second, Metrics: rec})
+
+	const requests = 3
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := hc.GetWithQuery(context.Background(), "/", nil, nil); err != nil {
+				t.Errorf("GetWithQuery() error = %v", err)
+			}
+		}()
+	}
+	for i := 0; i < requests; i++ {
+		<-received
+	}
+	if _, inFlight := rec.snapshot(); inFlight != requests {
+		t.Errorf("in-flight while requests block = %d, want %d", inFlight, requests)
+	}
+
+	close(release)
+	wg.Wait()
+	if _, inFlight := rec.snapshot(); inFlight != 0 {
+		t.Errorf("in-flight after requests finish = %d, want 0", inFlight)
+	}
+}
+
+func mustHost(t *testing.T, rawURL string) string {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatalf("parse %s: %v", rawURL, err)
+	}
+	return u.Host
+}
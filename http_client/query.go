@@ -0,0 +1,221 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// GetWithQuery sends a GET request with query parameters encoded from the
+// struct params.
+//
+// Fields are named by their `query:"name"` tag, or the field name when the
+// tag is absent, and skipped with `query:"-"`. Tag options:
+//
+//	omitempty  skip zero values
+//	comma      join slice elements with "," instead of repeating the key
+//
+// Strings, bools, integers, floats, time.Time and slices or pointers of those
+// are supported. Times use RFC 3339 unless a `layout:"2006-01-02"` tag is
+// given. Nil pointers are skipped. Embedded structs are flattened.
+func (hc *HTTPClient) GetWithQuery(ctx context.Context, endpoint string, params any, headers map[string]string, opts ...RequestOption) (*Response, error) {
+	query, err := encodeQuery(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode query params: %w", err)
+	}
+
+	options := newRequestOptions(opts)
+	url, err := hc.requestURL(endpoint, options)
+	if err != nil {
+		return nil, err
+	}
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+
+	req.SetRequestURI(url)
+	for _, param := range query {
+		req.URI().QueryArgs().Add(param.key, param.value)
+	}
+	req.Header.SetMethod(fasthttp.MethodGet)
+
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	response, err := hc.doRequest(ctx, req, resp, headers, options)
+	if err != nil {
+		return requestError(fasthttp.MethodGet, response, err)
+	}
+
+	return response, nil
+}
+
+type queryParam struct {
+	key   string
+	value string
+}
+
+type queryTag struct {
+	name      string
+	omitempty bool
+	comma     bool
+}
+
+func parseQueryTag(tag string) queryTag {
+	parts := strings.Split(tag, ",")
+	qt := queryTag{name: parts[0]}
+	for _, opt := range parts[1:] {
+		switch opt {
+		case "omitempty":
+			qt.omitempty = true
+		case "comma":
+			qt.comma = true
+		}
+	}
+	return qt
+}
+
+// encodeQuery flattens params into query parameters in field order.
+func encodeQuery(params any) ([]queryParam, error) {
+	if params == nil {
+		return nil, nil
+	}
+
+	v := reflect.ValueOf(params)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("params must be a struct, got %s", v.Type())
+	}
+
+	return appendStructQuery(nil, v)
+}
+
+func appendStructQuery(out []queryParam, v reflect.Value) ([]queryParam, error) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("query")
+		if tag == "-" {
+			continue
+		}
+		qt := parseQueryTag(tag)
+
+		fv := v.Field(i)
+		if field.Anonymous && qt.name == "" && indirectType(field.Type).Kind() == reflect.Struct && indirectType(field.Type) != timeType {
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			var err error
+			if out, err = appendStructQuery(out, fv); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if qt.name == "" {
+			qt.name = field.Name
+		}
+
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		if qt.omitempty && fv.IsZero() {
+			continue
+		}
+
+		values, err := queryValues(fv, field.Tag.Get("layout"))
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if qt.comma {
+			if len(values) > 0 {
+				out = append(out, queryParam{key: qt.name, value: strings.Join(values, ",")})
+			}
+			continue
+		}
+		for _, value := range values {
+			out = append(out, queryParam{key: qt.name, value: value})
+		}
+	}
+	return out, nil
+}
+
+func queryValues(v reflect.Value, layout string) ([]string, error) {
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		value, err := queryValue(v, layout)
+		if err != nil {
+			return nil, err
+		}
+		return []string{value}, nil
+	}
+
+	values := make([]string, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		elem := v.Index(i)
+		if elem.Kind() == reflect.Pointer {
+			if elem.IsNil() {
+				continue
+			}
+			elem = elem.Elem()
+		}
+		value, err := queryValue(elem, layout)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func queryValue(v reflect.Value, layout string) (string, error) {
+	if v.Type() == timeType {
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		return v.Interface().(time.Time).Format(layout), nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("unsupported query value of type %s", v.Type())
+	}
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Pointer {
+		return t.Elem()
+	}
+	return t
+}
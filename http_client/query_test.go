@@ -0,0 +1,204 @@
+package httpclient
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type queryPage struct {
+	Page  int `query:"page"`
+	Limit int `query:"limit,omitempty"`
+}
+
+type queryFilter struct {
+	Tag string `query:"tag"`
+}
+
+func TestEncodeQuery(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	name := "alice"
+	zero := 0
+	var nilInt *int
+
+	tests := []struct {
+		name    string
+		params  any
+		want    []queryParam
+		wantErr string
+	}{
+		{
+			name:   "nil params",
+			params: nil,
+		},
+		{
+			name:   "nil struct pointer",
+			params: (*queryPage)(nil),
+		},
+		{
+			name: "scalar kinds",
+			params: struct {
+				S   string  `query:"s"`
+				B   bool    `query:"b"`
+				I   int     `query:"i"`
+				I8  int8    `query:"i8"`
+				I64 int64   `query:"i64"`
+				U   uint    `query:"u"`
+				U16 uint16  `query:"u16"`
+				F32 float32 `query:"f32"`
+				F64 float64 `query:"f64"`
+			}{"a b", true, -1, 8, 1 << 40, 2, 16, 1.5, 0.1},
+			want: []queryParam{
+				{"s", "a b"}, {"b", "true"}, {"i", "-1"}, {"i8", "8"}, {"i64", "1099511627776"},
+				{"u", "2"}, {"u16", "16"}, {"f32", "1.5"}, {"f64", "0.1"},
+			},
+		},
+		{
+			name: "field name without tag",
+			params: struct {
+				Name string
+			}{"x"},
+			want: []queryParam{{"Name", "x"}},
+		},
+		{
+			name: "skipped and unexported fields",
+			params: struct {
+				Secret  string `query:"-"`
+				private string
+				Kept    string `query:"kept"`
+			}{"s", "p", "k"},
+			want: []queryParam{{"kept", "k"}},
+		},
+		{
+			name: "omitempty",
+			params: struct {
+				Empty  string    `query:"empty,omitempty"`
+				Zero   int       `query:"zero,omitempty"`
+				False  bool      `query:"false,omitempty"`
+				None   []string  `query:"none,omitempty"`
+				Kept   int       `query:"kept"`
+				ZeroTs time.Time `query:"ts,omitempty"`
+			}{},
+			want: []queryParam{{"kept", "0"}},
+		},
+		{
+			name: "time defaults to RFC 3339",
+			params: struct {
+				At time.Time `query:"at"`
+			}{ts},
+			want: []queryParam{{"at", "2024-05-01T10:30:00Z"}},
+		},
+		{
+			name: "time with layout",
+			params: struct {
+				Day time.Time `query:"day" layout:"2006-01-02"`
+			}{ts},
+			want: []queryParam{{"day", "2024-05-01"}},
+		},
+		{
+			name: "repeated slice",
+			params: struct {
+				IDs []int `query:"id"`
+			}{[]int{1, 2, 3}},
+			want: []queryParam{{"id", "1"}, {"id", "2"}, {"id", "3"}},
+		},
+		{
+			name: "comma slice",
+			params: struct {
+				Tags []string `query:"tags,comma"`
+			}{[]string{"a", "b"}},
+			want: []queryParam{{"tags", "a,b"}},
+		},
+		{
+			name: "empty comma slice",
+			params: struct {
+				Tags []string `query:"tags,comma"`
+			}{[]string{}},
+		},
+		{
+			name: "array and time slice with layout",
+			params: struct {
+				Days [2]time.Time `query:"day,comma" layout:"2006-01-02"`
+			}{[2]time.Time{ts, ts.AddDate(0, 0, 1)}},
+			want: []queryParam{{"day", "2024-05-01,2024-05-02"}},
+		},
+		{
+			name: "pointers",
+			params: struct {
+				Name *string `query:"name"`
+				Nil  *int    `query:"nil"`
+				Zero *int    `query:"zero,omitempty"`
+			}{&name, nilInt, &zero},
+			want: []queryParam{{"name", "alice"}},
+		},
+		{
+			name: "slice of pointers skips nil",
+			params: struct {
+				Names []*string `query:"name"`
+			}{[]*string{&name, nil}},
+			want: []queryParam{{"name", "alice"}},
+		},
+		{
+			name: "embedded structs are flattened",
+			params: &struct {
+				queryPage
+				*queryFilter
+				Q string `query:"q"`
+			}{queryPage{Page: 2}, &queryFilter{Tag: "go"}, "x"},
+			want: []queryParam{{"page", "2"}, {"tag", "go"}, {"q", "x"}},
+		},
+		{
+			name: "nil embedded pointer",
+			params: struct {
+				*queryFilter
+				Q string `query:"q"`
+			}{nil, "x"},
+			want: []queryParam{{"q", "x"}},
+		},
+		{
+			name: "nested struct field",
+			params: struct {
+				Filter queryFilter `query:"filter"`
+			}{queryFilter{Tag: "go"}},
+			wantErr: "field Filter: unsupported query value of type httpclient.queryFilter",
+		},
+		{
+			name:    "non-struct params",
+			params:  map[string]string{"a": "b"},
+			wantErr: "params must be a struct, got map[string]string",
+		},
+		{
+			name: "unsupported map field",
+			params: struct {
+				M map[string]string `query:"m"`
+			}{map[string]string{"a": "b"}},
+			wantErr: "field M: unsupported query value of type map[string]string",
+		},
+		{
+			name: "unsupported slice element",
+			params: struct {
+				C []complex64 `query:"c"`
+			}{[]complex64{1}},
+			wantErr: "field C: unsupported query value of type complex64",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := encodeQuery(tt.params)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("encodeQuery() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("encodeQuery() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("encodeQuery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
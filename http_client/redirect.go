@@ -0,0 +1,127 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// defaultMaxRedirects is used when FollowRedirects is set without a
+// MaxRedirects limit.
+const defaultMaxRedirects = 10
+
+// ErrTooManyRedirects is matched by errors.Is when a request exceeds
+// ClientConfig.MaxRedirects.
+var ErrTooManyRedirects = errors.New("too many redirects")
+
+// RedirectError reports the redirect that exceeded the limit.
+type RedirectError struct {
+	// Location is the redirect target that was not followed.
+	Location  string
+	Redirects int
+}
+
+func (e *RedirectError) Error() string {
+	return fmt.Sprintf("stopped after %d redirects, next location %s", e.Redirects, e.Location)
+}
+
+func (e *RedirectError) Unwrap() error {
+	return ErrTooManyRedirects
+}
+
+// send executes req and follows redirects when the client is configured to.
+func (hc *HTTPClient) send(ctx context.Context, client *fasthttp.Client, req *fasthttp.Request, resp *fasthttp.Response, opts requestOptions) error {
+	redirects := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := client.DoTimeout(req, resp, hc.timeout(ctx, opts.timeout)); err != nil {
+			return err
+		}
+
+		current := req.URI().String()
+		hc.storeCookies(resp, current)
+		if !hc.config.FollowRedirects || !fasthttp.StatusCodeIsRedirect(resp.StatusCode()) {
+			return nil
+		}
+		location := string(resp.Header.Peek(fasthttp.HeaderLocation))
+		if location == "" {
+			return nil
+		}
+
+		next, err := resolveRedirect(current, location)
+		if err != nil {
+			return err
+		}
+		if redirects >= hc.maxRedirects() {
+			return &RedirectError{Location: next.String(), Redirects: redirects}
+		}
+		if !hc.prepareRedirect(req, resp.StatusCode(), next) {
+			return nil
+		}
+		redirects++
+		hc.attachCookies(req, next.String())
+	}
+}
+
+func (hc *HTTPClient) maxRedirects() int {
+	if hc.config.MaxRedirects > 0 {
+		return hc.config.MaxRedirects
+	}
+	return defaultMaxRedirects
+}
+
+// prepareRedirect rewrites req to follow a redirect to next. It returns false
+// when the redirect must not be followed and the response is returned as is.
+//
+// 301, 302 and 303 turn every method but GET and HEAD into GET, dropping the
+// body, as net/http does. 307 and 308 keep the method and body, and are only
+// followed for requests with a body when RedirectResendBody is set; streamed
+// bodies cannot be re-sent and are never followed.
+func (hc *HTTPClient) prepareRedirect(req *fasthttp.Request, statusCode int, next *url.URL) bool {
+	method := string(req.Header.Method())
+	switch statusCode {
+	case fasthttp.StatusTemporaryRedirect, fasthttp.StatusPermanentRedirect:
+		if req.IsBodyStream() {
+			return false
+		}
+		if len(req.Body()) > 0 && !hc.config.RedirectResendBody {
+			return false
+		}
+	default:
+		if method != fasthttp.MethodGet && method != fasthttp.MethodHead {
+			switchToGet(req)
+		}
+	}
+
+	if !strings.EqualFold(string(req.URI().Host()), next.Host) && !hc.config.RedirectForwardAuth {
+		req.Header.Del(HeaderAuthorization)
+	}
+	req.Header.DelAllCookies()
+	req.SetRequestURI(next.String())
+	return true
+}
+
+func switchToGet(req *fasthttp.Request) {
+	req.Header.SetMethod(fasthttp.MethodGet)
+	req.ResetBody()
+	req.Header.Del(fasthttp.HeaderContentType)
+	req.Header.SetContentLength(0)
+}
+
+func resolveRedirect(current, location string) (*url.URL, error) {
+	base, err := url.Parse(current)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request url: %w", err)
+	}
+	ref, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redirect location %q: %w", location, err)
+	}
+	return base.ResolveReference(ref), nil
+}
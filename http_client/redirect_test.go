@@ -0,0 +1,160 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newRedirectServer redirects /start to /end with the status given in the
+// "status" query parameter and echoes the request that reaches /end.
+func newRedirectServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {
+		status := http.StatusFound
+		fmt.Sscan(r.URL.Query().Get("status"), &status)
+		http.Redirect(w, r, "/end", status)
+	})
+	mux.HandleFunc("/end", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, "%s|%s|%s", r.Method, body, r.Header.Get("Authorization"))
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRedirectMethodRewrite(t *testing.T) {
+	srv := newRedirectServer(t)
+
+	tests := []struct {
+		method     string
+		status     int
+		resendBody bool
+		wantStatus int
+		want       string
+	}{
+		{http.MethodGet, http.StatusMovedPermanently, false, http.StatusOK, "GET||"},
+		{http.MethodPost, http.StatusMovedPermanently, false, http.StatusOK, "GET||"},
+		{http.MethodPut, http.StatusFound, false, http.StatusOK, "GET||"},
+		{http.MethodPatch, http.StatusFound, false, http.StatusOK, "GET||"},
+		{http.MethodDelete, http.StatusFound, false, http.StatusOK, "GET||"},
+		{http.MethodPut, http.StatusSeeOther, false, http.StatusOK, "GET||"},
+		{http.MethodPut, http.StatusTemporaryRedirect, true, http.StatusOK, "PUT|payload|"},
+		{http.MethodPost, http.StatusPermanentRedirect, true, http.StatusOK, "POST|payload|"},
+		{http.MethodPut, http.StatusTemporaryRedirect, false, http.StatusTemporaryRedirect, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %d resend=%t", tt.method, tt.status, tt.resendBody), func(t *testing.T) {
+			hc := NewHTTPClient(ClientConfig{
+				BaseURL:            srv.URL,
+				Timeout:            5 * time.Second,
+				FollowRedirects:    true,
+				RedirectResendBody: tt.resendBody,
+			})
+
+			rb := hc.NewRequest(tt.method, fmt.Sprintf("/start?status=%d", tt.status))
+			if tt.method != http.MethodGet {
+				rb.Body([]byte("payload"), "text/plain")
+			}
+			resp, err := rb.Do(context.Background())
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && string(resp.Body) != tt.want {
+				t.Errorf("final request = %q, want %q", resp.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedirectAuthorization(t *testing.T) {
+	target := newRedirectServer(t)
+	// 127.0.0.1 and localhost reach the same listener under different
+	// hosts, which makes the redirect cross-host.
+	crossHost := strings.Replace(target.URL, "127.0.0.1", "localhost", 1)
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/same":
+			http.Redirect(w, r, "/end", http.StatusFound)
+		case "/cross":
+			http.Redirect(w, r, crossHost+"/end", http.StatusFound)
+		}
+	}))
+	t.Cleanup(origin.Close)
+	sameHost := newRedirectServer(t)
+
+	tests := []struct {
+		name        string
+		baseURL     string
+		endpoint    string
+		forwardAuth bool
+		want        string
+	}{
+		{"same host keeps auth", sameHost.URL, "/start", false, "GET||Bearer secret"},
+		{"cross host drops auth", origin.URL, "/cross", false, "GET||"},
+		{"cross host forwards auth when allowed", origin.URL, "/cross", true, "GET||Bearer secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc := NewHTTPClient(ClientConfig{
+				BaseURL:             tt.baseURL,
+				Timeout:             5 * time.Second,
+				FollowRedirects:     true,
+				RedirectForwardAuth: tt.forwardAuth,
+			}, WithBearerToken("secret"))
+
+			resp, err := hc.GetWithQuery(context.Background(), tt.endpoint, nil, nil)
+			if err != nil {
+				t.Fatalf("GetWithQuery() error = %v", err)
+			}
+			if string(resp.Body) != tt.want {
+				t.Errorf("final request = %q, want %q", resp.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedirectLimit(t *testing.T) {
+	srv := newRedirectServer(t)
+	hc := NewHTTPClient(ClientConfig{
+		BaseURL:         srv.URL,
+		Timeout:         5 * time.Second,
+		FollowRedirects: true,
+		MaxRedirects:    3,
+	})
+
+	_, err := hc.GetWithQuery(context.Background(), "/loop", nil, nil)
+	if !errors.Is(err, ErrTooManyRedirects) {
+		t.Fatalf("GetWithQuery() error = %v, want ErrTooManyRedirects", err)
+	}
+	var redirectErr *RedirectError
+	if !errors.As(err, &redirectErr) || redirectErr.Redirects != 3 || redirectErr.Location != srv.URL+"/loop" {
+		t.Errorf("RedirectError = %+v, want 3 redirects to %s/loop", redirectErr, srv.URL)
+	}
+
+	hc = NewHTTPClient(ClientConfig{BaseURL: srv.URL, Timeout: 5 * time.Second})
+	resp, err := hc.GetWithQuery(context.Background(), "/loop", nil, nil)
+	if err != nil {
+		t.Fatalf("GetWithQuery() without FollowRedirects error = %v", err)
+	}
+	if resp.StatusCode != http.StatusFound {
+		t.Errorf("status without FollowRedirects = %d, want %d", resp.StatusCode, http.StatusFound)
+	}
+}
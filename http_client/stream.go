@@ -0,0 +1,171 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/valyala/fasthttp"
+)
+
+// streamBufferSize is the largest response body the streaming client reads
+// into memory before switching to streaming.
+const streamBufferSize = 64 << 10
+
+// ErrStreamWrite is returned when the destination writer of a streamed
+// download fails or accepts fewer bytes than it was given.
+var ErrStreamWrite = errors.New("failed to write stream body")
+
+// GetStream sends a GET request and copies the response body into w without
+// buffering it in memory. The returned Response carries the status and
+// headers with a nil Body. Cancelling ctx stops the copy.
+func (hc *HTTPClient) GetStream(ctx context.Context, endpoint string, queryParams map[string]string, headers map[string]string, w io.Writer, opts ...RequestOption) (*Response, error) {
+	options := newRequestOptions(opts)
+	options.stream = true
+	url, err := hc.requestURL(endpoint, options)
+	if err != nil {
+		return nil, err
+	}
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+
+	req.SetRequestURI(url)
+	for key, value := range queryParams {
+		req.URI().QueryArgs().Add(key, value)
+	}
+	req.Header.SetMethod(fasthttp.MethodGet)
+
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	response, err := hc.doRequest(ctx, req, resp, headers, options)
+	if err != nil {
+		abortStream(resp)
+		return requestError(fasthttp.MethodGet, response, err)
+	}
+
+	if err := copyStream(ctx, w, resp, hc.config.EnableCompression); err != nil {
+		return response, err
+	}
+	return response, nil
+}
+
+// PostStream sends a POST request whose body is read from body rather than
+// held in memory. A contentLength of -1 sends the body with chunked transfer
+// encoding. An empty contentType falls back to ClientConfig.ContentType.
+//
+// A reader can only be consumed once, so streamed requests are never
+// retried.
+func (hc *HTTPClient) PostStream(ctx context.Context, endpoint string, body io.Reader, contentLength int64, contentType string, headers map[string]string, opts ...RequestOption) (*Response, error) {
+	options := newRequestOptions(opts)
+	url, err := hc.requestURL(endpoint, options)
+	if err != nil {
+		return nil, err
+	}
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+
+	if contentType == "" {
+		contentType = hc.config.ContentType
+	}
+	if contentLength < 0 {
+		contentLength = -1
+	}
+
+	req.SetRequestURI(url)
+	req.Header.SetMethod(fasthttp.MethodPost)
+	req.Header.SetContentType(contentType)
+	req.SetBodyStream(body, int(contentLength))
+
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	response, err := hc.doRequest(ctx, req, resp, headers, options)
+	if err != nil {
+		return requestError(fasthttp.MethodPost, response, err)
+	}
+
+	return response, nil
+}
+
+// copyStream copies the streamed body of resp into w, checking ctx between
+// chunks. When decompress is set the body is decoded per Content-Encoding.
+func copyStream(ctx context.Context, w io.Writer, resp *fasthttp.Response, decompress bool) error {
+	defer resp.CloseBodyStream()
+
+	body, err := streamBody(resp, decompress)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	dst := &streamWriter{ctx: ctx, w: w}
+	_, err = io.Copy(dst, body)
+	if err != nil {
+		abortStream(resp)
+	}
+	switch {
+	case err == nil:
+		return nil
+	case dst.err != nil:
+		return fmt.Errorf("%w: %w", ErrStreamWrite, dst.err)
+	case ctx.Err() != nil:
+		return ctx.Err()
+	default:
+		return fmt.Errorf("failed to read stream body: %w", err)
+	}
+}
+
+// abortStream closes a streamed body that was not read to the end. The
+// connection is closed rather than returned to the pool, where the unread
+// bytes would be taken for the next response.
+func abortStream(resp *fasthttp.Response) {
+	resp.SetConnectionClose()
+	resp.CloseBodyStream()
+}
+
+// streamBody returns the body of a response read by the streaming client,
+// which leaves bodies of up to streamBufferSize in memory instead of in
+// BodyStream. When decompress is set the body is decoded per
+// Content-Encoding.
+func streamBody(resp *fasthttp.Response, decompress bool) (io.ReadCloser, error) {
+	var body io.Reader = resp.BodyStream()
+	if body == nil {
+		body = bytes.NewReader(resp.Body())
+	}
+	if !decompress {
+		return io.NopCloser(body), nil
+	}
+	decoded, err := decodingReader(contentEncoding(resp), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode stream body: %w", err)
+	}
+	return decoded, nil
+}
+
+// streamWriter aborts the copy once ctx is done and records write failures
+// so they can be told apart from read failures.
+type streamWriter struct {
+	ctx context.Context
+	w   io.Writer
+	err error
+}
+
+func (sw *streamWriter) Write(p []byte) (int, error) {
+	if err := sw.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	n, err := sw.w.Write(p)
+	if err == nil && n < len(p) {
+		err = io.ErrShortWrite
+	}
+	if err != nil {
+		sw.err = err
+	}
+	return n, err
+}
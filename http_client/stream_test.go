@@ -0,0 +1,208 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newPayloadServer serves payload for every GET.
+func newPayloadServer(t *testing.T, payload []byte) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(payload)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func randomPayload(n int) []byte {
+	payload := make([]byte, n)
+	rand.New(rand.NewSource(1)).Read(payload)
+	return payload
+}
+
+func TestGetStreamCopiesBody(t *testing.T) {
+	payload := randomPayload(8 << 20)
+	srv := newPayloadServer(t, payload)
+	hc := NewHTTPClient(ClientConfig{BaseURL: srv.URL, Timeout: 5 * time.Second})
+
+	var buf bytes.Buffer
+	resp, err := hc.GetStream(context.Background(), "/", nil, nil, &buf)
+	if err != nil {
+		t.Fatalf("GetStream() error = %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Body != nil {
+		t.Errorf("GetStream() response = status %d, %d body bytes; want 200 and a nil body", resp.StatusCode, len(resp.Body))
+	}
+	if !bytes.Equal(buf.Bytes(), payload) {
+		t.Errorf("GetStream() wrote %d bytes that differ from the %d byte payload", buf.Len(), len(payload))
+	}
+}
+
+func TestGetStreamBoundedAllocation(t *testing.T) {
+	const size = 16 << 20
+	srv := newPayloadServer(t, randomPayload(size))
+	hc := NewHTTPClient(ClientConfig{BaseURL: srv.URL, Timeout: 5 * time.Second})
+
+	// Warm up connection pools so only the copy itself is measured.
+	if _, err := hc.GetStream(context.Background(), "/", nil, nil, io.Discard); err != nil {
+		t.Fatalf("GetStream() error = %v", err)
+	}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	if _, err := hc.GetStream(context.Background(), "/", nil, nil, io.Discard); err != nil {
+		t.Fatalf("GetStream() error = %v", err)
+	}
+	runtime.ReadMemStats(&after)
+
+	// The server side shares the process, so allow generous slack; a
+	// buffered body alone would be the full 16 MiB.
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > size/4 {
+		t.Errorf("GetStream() allocated %d bytes for a %d byte body", allocated, size)
+	}
+}
+
+type shortWriter struct{}
+
+func (shortWriter) Write(p []byte) (int, error) {
+	return len(p) / 2, nil
+}
+
+type failingWriter struct{ err error }
+
+func (w failingWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
+
+func TestGetStreamWriteErrors(t *testing.T) {
+	payload := randomPayload(1 << 20)
+	srv := newPayloadServer(t, payload)
+	hc := NewHTTPClient(ClientConfig{BaseURL: srv.URL, Timeout: 5 * time.Second})
+	errDisk := errors.New("disk full")
+
+	tests := []struct {
+		name string
+		w    io.Writer
+		want error
+	}{
+		{"short write", shortWriter{}, io.ErrShortWrite},
+		{"write error", failingWriter{errDisk}, errDisk},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := hc.GetStream(context.Background(), "/", nil, nil, tt.w)
+			if !errors.Is(err, ErrStreamWrite) || !errors.Is(err, tt.want) {
+				t.Errorf("GetStream() error = %v, want ErrStreamWrite wrapping %v", err, tt.want)
+			}
+
+			// The unread rest of the body must not leak into the next
+			// response on a reused connection.
+			var buf bytes.Buffer
+			if _, err := hc.GetStream(context.Background(), "/", nil, nil, &buf); err != nil {
+				t.Fatalf("GetStream() after a failed copy error = %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), payload) {
+				t.Errorf("GetStream() after a failed copy wrote %d bytes that differ from the payload", buf.Len())
+			}
+		})
+	}
+}
+
+// cancelWriter cancels its context after the first write.
+type cancelWriter struct {
+	cancel  context.CancelFunc
+	written int
+}
+
+func (w *cancelWriter) Write(p []byte) (int, error) {
+	w.written += len(p)
+	w.cancel()
+	return len(p), nil
+}
+
+func TestGetStreamCancelledMidStream(t *testing.T) {
+	chunk := randomPayload(32 << 10)
+	disconnected := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(disconnected)
+		for i := 0; i < 1000; i++ {
+			if _, err := w.Write(chunk); err != nil {
+				return
+			}
+			w.(http.Flusher).Flush()
+			time.Sleep(5 * time.Millisecond)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	hc := NewHTTPClient(ClientConfig{BaseURL: srv.URL, Timeout: 5 * time.Second})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := &cancelWriter{cancel: cancel}
+
+	start := time.Now()
+	_, err := hc.GetStream(ctx, "/", nil, nil, w)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("GetStream() error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("GetStream() returned after %s, want it to stop soon after cancel", elapsed)
+	}
+	if total := 1000 * len(chunk); w.written >= total {
+		t.Errorf("wrote the whole %d byte body despite cancellation", total)
+	}
+
+	// The connection must be closed, not pooled with the rest of the body
+	// still arriving.
+	select {
+	case <-disconnected:
+	case <-time.After(2 * time.Second):
+		t.Error("server kept sending after the stream was cancelled")
+	}
+}
+
+func TestPostStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, "%d|%v|%s|%s", r.ContentLength, r.TransferEncoding, r.Header.Get("Content-Type"), body)
+	}))
+	t.Cleanup(srv.Close)
+	hc := NewHTTPClient(ClientConfig{BaseURL: srv.URL, Timeout: 5 * time.Second, ContentType: ContentTypeJSON})
+
+	tests := []struct {
+		name          string
+		contentLength int64
+		contentType   string
+		want          string
+	}{
+		{"fixed length", 7, "text/plain", "7|[]|text/plain|payload"},
+		{"chunked", -1, "text/plain", "-1|[chunked]|text/plain|payload"},
+		{"any negative length is chunked", -5, "text/plain", "-1|[chunked]|text/plain|payload"},
+		{"default content type", 7, "", "7|[]|application/json|payload"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := hc.PostStream(context.Background(), "/", strings.NewReader("payload"), tt.contentLength, tt.contentType, nil)
+			if err != nil {
+				t.Fatalf("PostStream() error = %v", err)
+			}
+			if got := string(resp.Body); got != tt.want {
+				t.Errorf("server saw %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// JoinURL joins base and endpoint with exactly one slash between them, so
+// "http://host/api/" + "/users" and "http://host/api" + "users" both give
+// "http://host/api/users". An endpoint starting with "?" is appended as a
+// query string.
+func JoinURL(base, endpoint string) string {
+	if base == "" {
+		return endpoint
+	}
+	if endpoint == "" {
+		return base
+	}
+	if strings.HasPrefix(endpoint, "?") {
+		return strings.TrimRight(base, "/") + endpoint
+	}
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(endpoint, "/")
+}
+
+// requestURL resolves endpoint against the per-request base URL override,
+// or ClientConfig.BaseURL when there is none.
+func (hc *HTTPClient) requestURL(endpoint string, opts requestOptions) (string, error) {
+	base := hc.config.BaseURL
+	if opts.baseURL != "" {
+		if err := validateBaseURL(opts.baseURL); err != nil {
+			return "", err
+		}
+		base = opts.baseURL
+	}
+	return JoinURL(base, endpoint), nil
+}
+
+func validateBaseURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid base url %q: %w", rawURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid base url %q: scheme must be http or https", rawURL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid base url %q: missing host", rawURL)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("invalid base url %q: must not contain a query or fragment", rawURL)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestJoinURL(t *testing.T) {
+	tests := []struct {
+		base, endpoint, want string
+	}{
+		{"http://host/api", "users", "http://host/api/users"},
+		{"http://host/api", "/users", "http://host/api/users"},
+		{"http://host/api/", "users", "http://host/api/users"},
+		{"http://host/api/", "/users", "http://host/api/users"},
+		{"http://host/api//", "//users", "http://host/api/users"},
+		{"http://host", "users/1/", "http://host/users/1/"},
+		{"http://host/api/", "?page=2", "http://host/api?page=2"},
+		{"http://host/api", "", "http://host/api"},
+		{"", "/users", "/users"},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		if got := JoinURL(tt.base, tt.endpoint); got != tt.want {
+			t.Errorf("JoinURL(%q, %q) = %q, want %q", tt.base, tt.endpoint, got, tt.want)
+		}
+	}
+}
+
+func TestWithBaseURL(t *testing.T) {
+	newPathServer := func(name string) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(name + " " + r.URL.RequestURI()))
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+	primary := newPathServer("primary")
+	secondary := newPathServer("secondary")
+	hc := NewHTTPClient(ClientConfig{BaseURL: primary.URL + "/v1/", Timeout: 5 * time.Second})
+
+	tests := []struct {
+		name string
+		opts []RequestOption
+		want string
+	}{
+		{"config base url", nil, "primary /v1/users"},
+		{"override", []RequestOption{WithBaseURL(secondary.URL + "/v2")}, "secondary /v2/users"},
+		{"empty override keeps config", []RequestOption{WithBaseURL("")}, "primary /v1/users"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := hc.GetWithQuery(context.Background(), "/users", nil, nil, tt.opts...)
+			if err != nil {
+				t.Fatalf("GetWithQuery() error = %v", err)
+			}
+			if got := string(resp.Body); got != tt.want {
+				t.Errorf("request reached %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	for _, base := range []string{"ftp://host", "http://", "http://host?x=1", "http://host#frag", "://bad"} {
+		if _, err := hc.GetWithQuery(context.Background(), "/users", nil, nil, WithBaseURL(base)); err == nil {
+			t.Errorf("WithBaseURL(%q) succeeded, want an invalid base url error", base)
+		}
+	}
+}
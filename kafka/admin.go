@@ -0,0 +1,186 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/Shopify/sarama"
+)
+
+// ErrPartitionMismatch is returned by EnsureTopic when the topic exists with
+// a different partition count.
+var ErrPartitionMismatch = errors.New("kafka: topic partition count mismatch")
+
+// EnsureTopic creates topic name if it does not exist. If it already exists,
+// including when another client creates it concurrently, its partition count
+// is compared against partitions and ErrPartitionMismatch is returned when
+// they differ. Partitions are never added automatically, since that changes
+// which partition existing keys map to. Replication and configEntries only
+// apply on creation.
+func EnsureTopic(ctx context.Context, cf *Config, name string, partitions int32, replication int16, configEntries map[string]string) error {
+	if name == "" {
+		return errors.New("kafka: topic is required")
+	}
+
+	_, err := withAdmin(ctx, cf, func(admin sarama.ClusterAdmin) (struct{}, error) {
+		return struct{}{}, ensureTopic(admin, name, partitions, replication, configEntries)
+	})
+	return err
+}
+
+func ensureTopic(admin sarama.ClusterAdmin, name string, partitions int32, replication int16, configEntries map[string]string) error {
+	topics, err := admin.ListTopics()
+	if err != nil {
+		return fmt.Errorf("kafka: failed to list topics: %w", err)
+	}
+	if detail, ok := topics[name]; ok {
+		return checkPartitions(name, detail.NumPartitions, partitions)
+	}
+
+	detail := &sarama.TopicDetail{
+		NumPartitions:     partitions,
+		ReplicationFactor: replication,
+	}
+	if len(configEntries) > 0 {
+		detail.ConfigEntries = make(map[string]*string, len(configEntries))
+		for key, value := range configEntries {
+			detail.ConfigEntries[key] = &value
+		}
+	}
+
+	err = admin.CreateTopic(name, detail, false)
+	if errors.Is(err, sarama.ErrTopicAlreadyExists) {
+		metadata, err := describeTopic(admin, name)
+		if err != nil {
+			return err
+		}
+		return checkPartitions(name, int32(len(metadata.Partitions)), partitions)
+	}
+	if err != nil {
+		return fmt.Errorf("kafka: failed to create topic %s: %w", name, err)
+	}
+	return nil
+}
+
+// DeleteTopic deletes topic name.
+func DeleteTopic(ctx context.Context, cf *Config, name string) error {
+	_, err := withAdmin(ctx, cf, func(admin sarama.ClusterAdmin) (struct{}, error) {
+		if err := admin.DeleteTopic(name); err != nil {
+			return struct{}{}, fmt.Errorf("kafka: failed to delete topic %s: %w", name, err)
+		}
+		return struct{}{}, nil
+	})
+	return err
+}
+
+// ListTopics returns the names of all topics in the cluster, sorted.
+func ListTopics(ctx context.Context, cf *Config) ([]string, error) {
+	return withAdmin(ctx, cf, func(admin sarama.ClusterAdmin) ([]string, error) {
+		topics, err := admin.ListTopics()
+		if err != nil {
+			return nil, fmt.Errorf("kafka: failed to list topics: %w", err)
+		}
+		names := make([]string, 0, len(topics))
+		for name := range topics {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names, nil
+	})
+}
+
+// DescribeTopic returns the partition metadata of topic name.
+func DescribeTopic(ctx context.Context, cf *Config, name string) (*sarama.TopicMetadata, error) {
+	return withAdmin(ctx, cf, func(admin sarama.ClusterAdmin) (*sarama.TopicMetadata, error) {
+		return describeTopic(admin, name)
+	})
+}
+
+func describeTopic(admin sarama.ClusterAdmin, name string) (*sarama.TopicMetadata, error) {
+	metadata, err := admin.DescribeTopics([]string{name})
+	if err != nil {
+		return nil, fmt.Errorf("kafka: failed to describe topic %s: %w", name, err)
+	}
+	if len(metadata) == 0 {
+		return nil, fmt.Errorf("kafka: failed to describe topic %s: %w", name, sarama.ErrUnknownTopicOrPartition)
+	}
+	if metadata[0].Err != sarama.ErrNoError {
+		return nil, fmt.Errorf("kafka: failed to describe topic %s: %w", name, metadata[0].Err)
+	}
+	return metadata[0], nil
+}
+
+func checkPartitions(name string, got int32, want int32) error {
+	if got != want {
+		return fmt.Errorf("%w: topic %s has %d partitions, want %d", ErrPartitionMismatch, name, got, want)
+	}
+	return nil
+}
+
+// withAdmin runs fn with a ClusterAdmin for cf and returns its result.
+// sarama's connect and admin calls do not take a context, so both run in the
+// background and withAdmin returns as soon as ctx is done; the admin is
+// closed once they finish. The result travels over a channel, so a late fn
+// never writes to memory the caller still reads.
+func withAdmin[T any](ctx context.Context, cf *Config, fn func(sarama.ClusterAdmin) (T, error)) (T, error) {
+	return withClient(ctx, cf, func(_ sarama.Client, admin sarama.ClusterAdmin) (T, error) {
+		return fn(admin)
+	})
+}
+
+// withClient is withAdmin for callers that also need the underlying client.
+func withClient[T any](ctx context.Context, cf *Config, fn func(sarama.Client, sarama.ClusterAdmin) (T, error)) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		client, admin, err := newAdmin(cf)
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		defer admin.Close()
+		// Skip fn when the connect outlived ctx.
+		if err := ctx.Err(); err != nil {
+			done <- result{err: err}
+			return
+		}
+		value, err := fn(client, admin)
+		done <- result{value: value, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// newAdmin connects a client and a ClusterAdmin sharing it. Closing the
+// admin closes the client.
+func newAdmin(cf *Config) (sarama.Client, sarama.ClusterAdmin, error) {
+	config, err := cf.BuildSaramaConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := sarama.NewClient(cf.Address, config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("kafka: failed to connect to %v: %w", cf.Address, err)
+	}
+	admin, err := sarama.NewClusterAdminFromClient(client)
+	if err != nil {
+		client.Close()
+		return nil, nil, fmt.Errorf("kafka: failed to create cluster admin for %v: %w", cf.Address, err)
+	}
+	return client, admin, nil
+}
@@ -0,0 +1,181 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+// fakeAdmin implements the ClusterAdmin calls used by ensureTopic.
+type fakeAdmin struct {
+	sarama.ClusterAdmin
+	topics    map[string]sarama.TopicDetail
+	createErr error
+	// racePartitions is the partition count DescribeTopics reports for a
+	// topic created concurrently by someone else.
+	racePartitions int
+	created        map[string]*sarama.TopicDetail
+}
+
+func (a *fakeAdmin) ListTopics() (map[string]sarama.TopicDetail, error) {
+	return a.topics, nil
+}
+
+func (a *fakeAdmin) CreateTopic(name string, detail *sarama.TopicDetail, _ bool) error {
+	if a.createErr != nil {
+		return a.createErr
+	}
+	if a.created == nil {
+		a.created = make(map[string]*sarama.TopicDetail)
+	}
+	a.created[name] = detail
+	return nil
+}
+
+func (a *fakeAdmin) DescribeTopics(names []string) ([]*sarama.TopicMetadata, error) {
+	return []*sarama.TopicMetadata{{
+		Name:       names[0],
+		Partitions: make([]*sarama.PartitionMetadata, a.racePartitions),
+	}}, nil
+}
+
+func TestEnsureTopic(t *testing.T) {
+	tests := []struct {
+		name        string
+		admin       *fakeAdmin
+		wantErr     error
+		wantCreated bool
+	}{
+		{
+			name:        "create",
+			admin:       &fakeAdmin{},
+			wantCreated: true,
+		},
+		{
+			name:  "exists with same partitions",
+			admin: &fakeAdmin{topics: map[string]sarama.TopicDetail{"orders": {NumPartitions: 6}}},
+		},
+		{
+			name:    "exists with different partitions",
+			admin:   &fakeAdmin{topics: map[string]sarama.TopicDetail{"orders": {NumPartitions: 3}}},
+			wantErr: ErrPartitionMismatch,
+		},
+		{
+			name:  "created concurrently with same partitions",
+			admin: &fakeAdmin{createErr: sarama.ErrTopicAlreadyExists, racePartitions: 6},
+		},
+		{
+			name:    "created concurrently with different partitions",
+			admin:   &fakeAdmin{createErr: sarama.ErrTopicAlreadyExists, racePartitions: 12},
+			wantErr: ErrPartitionMismatch,
+		},
+		{
+			name:    "create fails",
+			admin:   &fakeAdmin{createErr: sarama.ErrInvalidReplicationFactor},
+			wantErr: sarama.ErrInvalidReplicationFactor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ensureTopic(tt.admin, "orders", 6, 3, map[string]string{"retention.ms": "60000"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ensureTopic() error = %v, want %v", err, tt.wantErr)
+			}
+
+			detail, created := tt.admin.created["orders"]
+			if created != tt.wantCreated {
+				t.Fatalf("created = %v, want %v", created, tt.wantCreated)
+			}
+			if created {
+				if detail.NumPartitions != 6 || detail.ReplicationFactor != 3 {
+					t.Errorf("detail = %+v, want 6 partitions, replication 3", detail)
+				}
+				if got := detail.ConfigEntries["retention.ms"]; got == nil || *got != "60000" {
+					t.Errorf("retention.ms = %v, want 60000", got)
+				}
+			}
+		})
+	}
+}
+
+func newMockCluster(t *testing.T) (*sarama.MockBroker, *Config) {
+	t.Helper()
+	broker := sarama.NewMockBroker(t, 1)
+	t.Cleanup(broker.Close)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetController(broker.BrokerID()).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("payments", 0, broker.BrokerID()),
+		"CreateTopicsRequest":    sarama.NewMockCreateTopicsResponse(t),
+		"DescribeConfigsRequest": sarama.NewMockDescribeConfigsResponse(t),
+	})
+
+	config := sarama.NewConfig()
+	config.Version = sarama.V1_0_0_0
+	config.Metadata.Retry.Max = 0
+	return broker, &Config{Address: []string{broker.Addr()}, Config: *config}
+}
+
+func TestAdminMockBroker(t *testing.T) {
+	_, cf := newMockCluster(t)
+	ctx := context.Background()
+
+	if err := EnsureTopic(ctx, cf, "orders", 1, 1, nil); err != nil {
+		t.Fatalf("EnsureTopic() error = %v", err)
+	}
+	if err := EnsureTopic(ctx, cf, "payments", 1, 1, nil); err != nil {
+		t.Fatalf("EnsureTopic() for existing topic error = %v", err)
+	}
+	if err := EnsureTopic(ctx, cf, "payments", 3, 1, nil); !errors.Is(err, ErrPartitionMismatch) {
+		t.Fatalf("EnsureTopic() error = %v, want ErrPartitionMismatch", err)
+	}
+
+	topics, err := ListTopics(ctx, cf)
+	if err != nil {
+		t.Fatalf("ListTopics() error = %v", err)
+	}
+	if !slices.Equal(topics, []string{"payments"}) {
+		t.Errorf("ListTopics() = %v, want [payments]", topics)
+	}
+}
+
+// A broker that accepts connections but never answers must not hold the
+// caller past ctx.
+func TestAdminRespectsContextDuringConnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+
+	config := sarama.NewConfig()
+	config.Net.ReadTimeout = 10 * time.Second
+	cf := &Config{Address: []string{ln.Addr().String()}, Config: *config}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = ListTopics(ctx, cf)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ListTopics() error = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("ListTopics() returned after %s", elapsed)
+	}
+}
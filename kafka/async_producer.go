@@ -0,0 +1,167 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/Shopify/sarama"
+)
+
+var (
+	// ErrProducerClosed is returned by Send after Close has been called.
+	ErrProducerClosed = errors.New("kafka: producer is closed")
+	// ErrProducerFull is returned by Send with BackpressureError when the
+	// producer's input buffer is full.
+	ErrProducerFull = errors.New("kafka: producer input is full")
+)
+
+// Backpressure decides what Send does when the producer's input buffer
+// (sarama's ChannelBufferSize) is full.
+type Backpressure int
+
+const (
+	// BackpressureBlock waits until there is room or ctx is done.
+	BackpressureBlock Backpressure = iota
+	// BackpressureError fails fast with ErrProducerFull.
+	BackpressureError
+)
+
+// AsyncProducerOptions configures the delivery callbacks of an AsyncProducer.
+// Callbacks run on the producer's own goroutines and must not block for long,
+// or they stall delivery reporting.
+type AsyncProducerOptions struct {
+	OnSuccess    func(msg *sarama.ProducerMessage)
+	OnError      func(msg *sarama.ProducerMessage, err error)
+	Backpressure Backpressure
+}
+
+// AsyncProducer wraps sarama.AsyncProducer, routing delivery reports to
+// callbacks.
+type AsyncProducer struct {
+	producer sarama.AsyncProducer
+	client   sarama.Client
+	opts     AsyncProducerOptions
+
+	mu        sync.RWMutex
+	closed    bool
+	closing   chan struct{}
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// NewAsyncProducer creates an AsyncProducer on cf.Address. Producer.Return
+// Successes and Errors are always enabled so every message reaches a
+// callback.
+func (cf *Config) NewAsyncProducer(opts AsyncProducerOptions) (*AsyncProducer, error) {
+	config, err := cf.BuildSaramaConfig()
+	if err != nil {
+		return nil, err
+	}
+	config.Producer.Return.Successes = true
+	config.Producer.Return.Errors = true
+
+	client, err := sarama.NewClient(cf.Address, config)
+	if err != nil {
+		return nil, err
+	}
+	producer, err := sarama.NewAsyncProducerFromClient(client)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	p := newAsyncProducer(producer, opts)
+	p.client = client
+	return p, nil
+}
+
+func newAsyncProducer(producer sarama.AsyncProducer, opts AsyncProducerOptions) *AsyncProducer {
+	p := &AsyncProducer{
+		producer: producer,
+		opts:     opts,
+		closing:  make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for msg := range producer.Successes() {
+			if p.opts.OnSuccess != nil {
+				p.opts.OnSuccess(msg)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for perr := range producer.Errors() {
+			if p.opts.OnError != nil {
+				p.opts.OnError(perr.Msg, perr.Err)
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		if p.client != nil {
+			p.client.Close()
+		}
+		close(p.done)
+	}()
+	return p
+}
+
+// Ping reports whether at least one broker connection is alive.
+func (p *AsyncProducer) Ping(ctx context.Context) error {
+	return pingClient(ctx, p.client)
+}
+
+// Send queues msg for delivery. The outcome is reported to OnSuccess or
+// OnError; the returned error only covers queueing.
+func (p *AsyncProducer) Send(ctx context.Context, msg *sarama.ProducerMessage) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrProducerClosed
+	}
+
+	if p.opts.Backpressure == BackpressureError {
+		select {
+		case p.producer.Input() <- msg:
+			return nil
+		default:
+			return ErrProducerFull
+		}
+	}
+
+	select {
+	case p.producer.Input() <- msg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.closing:
+		return ErrProducerClosed
+	}
+}
+
+// Close stops accepting messages and flushes the ones already queued,
+// waiting until every delivery report has reached its callback or ctx is
+// done. Reports that arrive after ctx ends still reach the callbacks. It is
+// safe to call more than once.
+func (p *AsyncProducer) Close(ctx context.Context) error {
+	p.closeOnce.Do(func() {
+		close(p.closing)
+		p.mu.Lock()
+		p.closed = true
+		p.mu.Unlock()
+		p.producer.AsyncClose()
+	})
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("kafka: producer did not flush: %w", ctx.Err())
+	}
+}
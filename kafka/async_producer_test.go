@@ -0,0 +1,227 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+)
+
+// deliveries collects the delivery reports of an AsyncProducer.
+type deliveries struct {
+	mu        sync.Mutex
+	succeeded []string
+	failed    map[string]error
+}
+
+func (d *deliveries) options() AsyncProducerOptions {
+	return AsyncProducerOptions{
+		OnSuccess: func(msg *sarama.ProducerMessage) {
+			d.mu.Lock()
+			defer d.mu.Unlock()
+			d.succeeded = append(d.succeeded, string(msg.Key.(sarama.StringEncoder)))
+		},
+		OnError: func(msg *sarama.ProducerMessage, err error) {
+			d.mu.Lock()
+			defer d.mu.Unlock()
+			if d.failed == nil {
+				d.failed = make(map[string]error)
+			}
+			d.failed[string(msg.Key.(sarama.StringEncoder))] = err
+		},
+	}
+}
+
+func newMockAsyncProducer(t *testing.T) *mocks.AsyncProducer {
+	config := mocks.NewTestConfig()
+	config.Producer.Return.Successes = true
+	config.Producer.Return.Errors = true
+	return mocks.NewAsyncProducer(t, config)
+}
+
+func keyed(key string) *sarama.ProducerMessage {
+	return &sarama.ProducerMessage{Topic: "orders", Key: sarama.StringEncoder(key), Value: sarama.StringEncoder("v")}
+}
+
+func TestAsyncProducerDeliveryCallbacks(t *testing.T) {
+	mock := newMockAsyncProducer(t)
+	mock.ExpectInputAndSucceed()
+	mock.ExpectInputAndFail(sarama.ErrMessageSizeTooLarge)
+
+	var got deliveries
+	p := newAsyncProducer(mock, got.options())
+	ctx := context.Background()
+	if err := p.Send(ctx, keyed("ok")); err != nil {
+		t.Fatalf("Send(ok) error = %v", err)
+	}
+	if err := p.Send(ctx, keyed("too-large")); err != nil {
+		t.Fatalf("Send(too-large) error = %v", err)
+	}
+	if err := p.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if len(got.succeeded) != 1 || got.succeeded[0] != "ok" {
+		t.Errorf("OnSuccess keys = %v, want [ok]", got.succeeded)
+	}
+	if len(got.failed) != 1 || !errors.Is(got.failed["too-large"], sarama.ErrMessageSizeTooLarge) {
+		t.Errorf("OnError reports = %v, want too-large: %v", got.failed, sarama.ErrMessageSizeTooLarge)
+	}
+}
+
+func TestAsyncProducerCloseFlushes(t *testing.T) {
+	mock := newMockAsyncProducer(t)
+	const n = 50
+	for i := 0; i < n; i++ {
+		if i%5 == 0 {
+			mock.ExpectInputAndFail(sarama.ErrNotLeaderForPartition)
+		} else {
+			mock.ExpectInputAndSucceed()
+		}
+	}
+
+	var got deliveries
+	p := newAsyncProducer(mock, got.options())
+	ctx := context.Background()
+	for i := 0; i < n; i++ {
+		if err := p.Send(ctx, keyed(strconv.Itoa(i))); err != nil {
+			t.Fatalf("Send(%d) error = %v", i, err)
+		}
+	}
+
+	// Every report must have reached a callback by the time Close returns;
+	// no locking or waiting is needed to read them.
+	if err := p.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if len(got.succeeded) != n-n/5 || len(got.failed) != n/5 {
+		t.Errorf("after Close: %d successes and %d errors, want %d and %d",
+			len(got.succeeded), len(got.failed), n-n/5, n/5)
+	}
+
+	if err := p.Send(ctx, keyed("late")); !errors.Is(err, ErrProducerClosed) {
+		t.Errorf("Send() after Close error = %v, want ErrProducerClosed", err)
+	}
+	if err := p.Close(ctx); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+}
+
+// stalledProducer never reads its input, so it is always full.
+type stalledProducer struct {
+	sarama.AsyncProducer
+	input     chan *sarama.ProducerMessage
+	successes chan *sarama.ProducerMessage
+	errs      chan *sarama.ProducerError
+}
+
+func newStalledProducer() *stalledProducer {
+	return &stalledProducer{
+		input:     make(chan *sarama.ProducerMessage),
+		successes: make(chan *sarama.ProducerMessage),
+		errs:      make(chan *sarama.ProducerError),
+	}
+}
+
+func (p *stalledProducer) Input() chan<- *sarama.ProducerMessage     { return p.input }
+func (p *stalledProducer) Successes() <-chan *sarama.ProducerMessage { return p.successes }
+func (p *stalledProducer) Errors() <-chan *sarama.ProducerError      { return p.errs }
+func (p *stalledProducer) AsyncClose() {
+	close(p.successes)
+	close(p.errs)
+}
+
+func TestAsyncProducerBackpressure(t *testing.T) {
+	t.Run("error", func(t *testing.T) {
+		p := newAsyncProducer(newStalledProducer(), AsyncProducerOptions{Backpressure: BackpressureError})
+		defer p.Close(context.Background())
+
+		start := time.Now()
+		if err := p.Send(context.Background(), keyed("a")); !errors.Is(err, ErrProducerFull) {
+			t.Fatalf("Send() error = %v, want ErrProducerFull", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Send() took %v, want an immediate failure", elapsed)
+		}
+	})
+
+	t.Run("block until ctx done", func(t *testing.T) {
+		p := newAsyncProducer(newStalledProducer(), AsyncProducerOptions{Backpressure: BackpressureBlock})
+		defer p.Close(context.Background())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		if err := p.Send(ctx, keyed("a")); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Send() error = %v, want context.DeadlineExceeded", err)
+		}
+	})
+
+	t.Run("block until Close", func(t *testing.T) {
+		p := newAsyncProducer(newStalledProducer(), AsyncProducerOptions{})
+
+		done := make(chan error, 1)
+		go func() {
+			done <- p.Send(context.Background(), keyed("a"))
+		}()
+		time.Sleep(20 * time.Millisecond)
+		closeErr := make(chan error, 1)
+		go func() {
+			closeErr <- p.Close(context.Background())
+		}()
+
+		select {
+		case err := <-done:
+			if !errors.Is(err, ErrProducerClosed) {
+				t.Errorf("blocked Send() error = %v, want ErrProducerClosed", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("blocked Send() did not return after Close")
+		}
+		if err := <-closeErr; err != nil {
+			t.Errorf("Close() error = %v", err)
+		}
+	})
+}
+
+// slowProducer reports one failed message only after release is closed,
+// simulating a flush that outlasts Close's deadline.
+type slowProducer struct {
+	*stalledProducer
+	release chan struct{}
+}
+
+func (p *slowProducer) AsyncClose() {
+	go func() {
+		<-p.release
+		p.errs <- &sarama.ProducerError{Msg: keyed("pending"), Err: sarama.ErrRequestTimedOut}
+		p.stalledProducer.AsyncClose()
+	}()
+}
+
+func TestAsyncProducerCloseDeadline(t *testing.T) {
+	producer := &slowProducer{stalledProducer: newStalledProducer(), release: make(chan struct{})}
+	var got deliveries
+	p := newAsyncProducer(producer, got.options())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := p.Close(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.HasPrefix(err.Error(), "kafka: producer did not flush: ") {
+		t.Fatalf("Close() error = %v, want a flush deadline error", err)
+	}
+
+	// The pending report still reaches OnError once the flush completes.
+	close(producer.release)
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatalf("Close() after flush error = %v", err)
+	}
+	if !errors.Is(got.failed["pending"], sarama.ErrRequestTimedOut) {
+		t.Errorf("OnError reports = %v, want pending: %v", got.failed, sarama.ErrRequestTimedOut)
+	}
+}
@@ -0,0 +1,138 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+// BatchItem is one message for PublishBatch. Value is marshalled as JSON
+// like in Publish.
+type BatchItem struct {
+	Key     string
+	Value   any
+	Headers map[string]string
+}
+
+// BatchResult is the outcome of the BatchItem at Index.
+type BatchResult struct {
+	Index     int
+	Partition int32
+	Offset    int64
+	Err       error
+}
+
+// WithMaxBatchBytes caps the payload bytes PublishBatch sends per
+// SendMessages call. Defaults to DefaultMaxMessageBytes.
+func WithMaxBatchBytes(n int) PublishOption {
+	return func(o *publishOptions) {
+		o.maxBatchBytes = n
+	}
+}
+
+// PublishBatch sends items to topic with SendMessages, splitting them into
+// chunks of at most the WithMaxBatchBytes size. It returns one result per
+// item in input order. Items that fail to encode, are too large or are
+// rejected by the broker carry their own Err; the returned error is non-nil
+// when any item failed.
+func PublishBatch(ctx context.Context, producer sarama.SyncProducer, topic string, items []BatchItem, opts ...PublishOption) ([]BatchResult, error) {
+	if topic == "" {
+		return nil, errors.New("kafka: topic is required")
+	}
+
+	options := newPublishOptions(opts)
+	results := make([]BatchResult, len(items))
+
+	var (
+		chunk      []*sarama.ProducerMessage
+		chunkIndex = make(map[*sarama.ProducerMessage]int)
+		chunkBytes int
+	)
+	flush := func() {
+		if len(chunk) == 0 {
+			return
+		}
+		sendBatch(ctx, producer, topic, chunk, chunkIndex, results, options)
+		chunk = nil
+		chunkIndex = make(map[*sarama.ProducerMessage]int)
+		chunkBytes = 0
+	}
+
+	for i, item := range items {
+		results[i].Index = i
+
+		payload, err := json.Marshal(item.Value)
+		if err != nil {
+			results[i].Err = fmt.Errorf("kafka: failed to marshal message for %s: %w", topic, err)
+			continue
+		}
+		msg, err := newProducerMessage(topic, item.Key, payload, item.Headers, options)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		size := msg.ByteSize(2)
+		if len(chunk) > 0 && options.maxBatchBytes > 0 && chunkBytes+size > options.maxBatchBytes {
+			flush()
+		}
+		chunk = append(chunk, msg)
+		chunkIndex[msg] = i
+		chunkBytes += size
+	}
+	flush()
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("kafka: %d of %d messages failed to publish to %s", failed, len(items), topic)
+	}
+	return results, nil
+}
+
+// sendBatch sends one chunk and records each message's outcome in results.
+func sendBatch(ctx context.Context, producer sarama.SyncProducer, topic string, chunk []*sarama.ProducerMessage, index map[*sarama.ProducerMessage]int, results []BatchResult, options publishOptions) {
+	if err := ctx.Err(); err != nil {
+		for _, msg := range chunk {
+			results[index[msg]].Err = err
+		}
+		return
+	}
+
+	start := time.Now()
+	err := producer.SendMessages(chunk)
+	if options.metrics != nil {
+		options.metrics.ObservePublish(topic, metricsOutcome(err), len(chunk), time.Since(start))
+	}
+
+	failed := make(map[*sarama.ProducerMessage]error)
+	var producerErrs sarama.ProducerErrors
+	switch {
+	case errors.As(err, &producerErrs):
+		for _, perr := range producerErrs {
+			failed[perr.Msg] = fmt.Errorf("kafka: failed to publish to %s: %w", topic, perr.Err)
+		}
+	case err != nil:
+		for _, msg := range chunk {
+			failed[msg] = fmt.Errorf("kafka: failed to publish to %s: %w", topic, err)
+		}
+	}
+
+	for _, msg := range chunk {
+		i := index[msg]
+		if err, ok := failed[msg]; ok {
+			results[i].Err = err
+			continue
+		}
+		results[i].Partition = msg.Partition
+		results[i].Offset = msg.Offset
+	}
+}
@@ -0,0 +1,187 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Shopify/sarama"
+)
+
+// batchProducer records each SendMessages chunk and fails the messages
+// whose key is in fail with a sarama.ProducerErrors, like the real producer.
+type batchProducer struct {
+	sarama.SyncProducer
+	fail   map[string]error
+	chunks [][]string
+	offset int64
+}
+
+func (p *batchProducer) SendMessages(msgs []*sarama.ProducerMessage) error {
+	var keys []string
+	var errs sarama.ProducerErrors
+	for _, msg := range msgs {
+		key, _ := msg.Key.Encode()
+		keys = append(keys, string(key))
+		if err, ok := p.fail[string(key)]; ok {
+			errs = append(errs, &sarama.ProducerError{Msg: msg, Err: err})
+			continue
+		}
+		p.offset++
+		msg.Partition = 1
+		msg.Offset = p.offset
+	}
+	p.chunks = append(p.chunks, keys)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func TestPublishBatch(t *testing.T) {
+	producer := newMockSyncProducer(t)
+	var sent []string
+	for i := 0; i < 3; i++ {
+		producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+			sent = append(sent, encoded(t, msg.Key)+"="+encoded(t, msg.Value))
+			if headerMap(msg.Headers)["source"] != "batch" {
+				t.Errorf("headers = %v, want source=batch", headerMap(msg.Headers))
+			}
+			return nil
+		})
+	}
+
+	headers := map[string]string{"source": "batch"}
+	items := []BatchItem{
+		{Key: "a", Value: map[string]int{"n": 1}, Headers: headers},
+		{Key: "b", Value: "two", Headers: headers},
+		{Key: "c", Value: 3, Headers: headers},
+	}
+	results, err := PublishBatch(context.Background(), producer, "events", items)
+	if err != nil {
+		t.Fatalf("PublishBatch() error = %v", err)
+	}
+
+	if want := []string{`a={"n":1}`, `b="two"`, "c=3"}; strings.Join(sent, " ") != strings.Join(want, " ") {
+		t.Errorf("sent = %v, want %v", sent, want)
+	}
+	if len(results) != len(items) {
+		t.Fatalf("got %d results, want %d", len(results), len(items))
+	}
+	for i, result := range results {
+		if result.Index != i || result.Err != nil || result.Offset != int64(i+1) {
+			t.Errorf("results[%d] = %+v, want index %d, offset %d and no error", i, result, i, i+1)
+		}
+	}
+}
+
+func TestPublishBatchPartialFailure(t *testing.T) {
+	producer := &batchProducer{fail: map[string]error{
+		"b": sarama.ErrNotLeaderForPartition,
+		"d": sarama.ErrMessageSizeTooLarge,
+	}}
+	items := []BatchItem{
+		{Key: "a", Value: 1},
+		{Key: "b", Value: 2},
+		{Key: "bad", Value: make(chan int)},
+		{Key: "d", Value: 4},
+		{Key: "e", Value: 5},
+	}
+	results, err := PublishBatch(context.Background(), producer, "events", items)
+	if err == nil || err.Error() != "kafka: 3 of 5 messages failed to publish to events" {
+		t.Fatalf("PublishBatch() error = %v, want 3 of 5 failed", err)
+	}
+
+	wantErr := map[int]error{1: sarama.ErrNotLeaderForPartition, 3: sarama.ErrMessageSizeTooLarge}
+	for i, result := range results {
+		switch {
+		case i == 2:
+			if result.Err == nil || !strings.HasPrefix(result.Err.Error(), "kafka: failed to marshal message for events: ") {
+				t.Errorf("results[2].Err = %v, want a marshal error", result.Err)
+			}
+		case wantErr[i] != nil:
+			if !errors.Is(result.Err, wantErr[i]) {
+				t.Errorf("results[%d].Err = %v, want %v", i, result.Err, wantErr[i])
+			}
+		default:
+			if result.Err != nil || result.Partition != 1 || result.Offset == 0 {
+				t.Errorf("results[%d] = %+v, want a delivered message", i, result)
+			}
+		}
+		if result.Index != i {
+			t.Errorf("results[%d].Index = %d", i, result.Index)
+		}
+	}
+	if len(producer.chunks) != 1 || strings.Join(producer.chunks[0], "") != "abde" {
+		t.Errorf("chunks = %v, want one chunk without the unencodable item", producer.chunks)
+	}
+}
+
+func TestPublishBatchSplitsBySize(t *testing.T) {
+	var items []BatchItem
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		items = append(items, BatchItem{Key: key, Value: strings.Repeat("x", 100)})
+	}
+	msg, err := newProducerMessage("events", "a", []byte(`"`+strings.Repeat("x", 100)+`"`), nil, newPublishOptions(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := msg.ByteSize(2)
+
+	tests := []struct {
+		name     string
+		maxBytes int
+		want     string
+	}{
+		{"two per chunk", 2*size + size/2, "ab cd e"},
+		{"exact fit", 2 * size, "ab cd e"},
+		{"one per chunk", size, "a b c d e"},
+		{"smaller than one message", size / 2, "a b c d e"},
+		{"unlimited", 0, "abcde"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			producer := &batchProducer{}
+			results, err := PublishBatch(context.Background(), producer, "events", items, WithMaxBatchBytes(tt.maxBytes))
+			if err != nil {
+				t.Fatalf("PublishBatch() error = %v", err)
+			}
+			var chunks []string
+			for _, chunk := range producer.chunks {
+				chunks = append(chunks, strings.Join(chunk, ""))
+			}
+			if got := strings.Join(chunks, " "); got != tt.want {
+				t.Errorf("chunks = %q, want %q", got, tt.want)
+			}
+			for i, result := range results {
+				if result.Offset != int64(i+1) {
+					t.Errorf("results[%d].Offset = %d, want %d", i, result.Offset, i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestPublishBatchErrors(t *testing.T) {
+	if _, err := PublishBatch(context.Background(), &batchProducer{}, "", []BatchItem{{Value: 1}}); err == nil {
+		t.Error("PublishBatch() without a topic succeeded")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	producer := &batchProducer{}
+	results, err := PublishBatch(ctx, producer, "events", []BatchItem{{Key: "a", Value: 1}, {Key: "b", Value: 2}})
+	if err == nil {
+		t.Fatal("PublishBatch() with a cancelled ctx succeeded")
+	}
+	for i, result := range results {
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("results[%d].Err = %v, want context.Canceled", i, result.Err)
+		}
+	}
+	if len(producer.chunks) != 0 {
+		t.Errorf("sent %d chunks after ctx was cancelled", len(producer.chunks))
+	}
+}
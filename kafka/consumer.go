@@ -0,0 +1,280 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/11SF/go-common/logger"
+	"github.com/Shopify/sarama"
+	"go.uber.org/zap"
+)
+
+// Handler processes one consumed message. Returning an error, or panicking,
+// marks the message as failed.
+type Handler func(ctx context.Context, msg *sarama.ConsumerMessage) error
+
+// ErrConsumerClosed is returned by Run after Close has been called.
+var ErrConsumerClosed = errors.New("kafka: consumer group is closed")
+
+// ConsumerGroup runs a sarama consumer group loop for Config.Group.
+type ConsumerGroup struct {
+	group   sarama.ConsumerGroup
+	client  sarama.Client
+	log     *zap.Logger
+	retry   RetryPolicy
+	dlq     *deadLetterQueue
+	metrics MetricsRecorder
+
+	ready     chan struct{}
+	readyOnce sync.Once
+
+	mu        sync.Mutex
+	closed    bool
+	running   sync.WaitGroup
+	stop      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+}
+
+type ConsumerOption func(*ConsumerGroup)
+
+// WithConsumerLogger sets the logger used for handler failures and consumer
+// errors. The default logs at info level.
+func WithConsumerLogger(log *zap.Logger) ConsumerOption {
+	return func(cg *ConsumerGroup) {
+		cg.log = log
+	}
+}
+
+// NewConsumerGroup joins cf.Group on cf.Address. Consumer.Return.Errors is
+// always enabled so Run can log errors instead of leaving them to sarama.
+func (cf *Config) NewConsumerGroup(opts ...ConsumerOption) (*ConsumerGroup, error) {
+	if cf.Group == "" {
+		return nil, errors.New("kafka: consumer group name is required")
+	}
+
+	config, err := cf.BuildSaramaConfig()
+	if err != nil {
+		return nil, err
+	}
+	config.Consumer.Return.Errors = true
+
+	client, err := sarama.NewClient(cf.Address, config)
+	if err != nil {
+		return nil, err
+	}
+	group, err := sarama.NewConsumerGroupFromClient(cf.Group, client)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	cg := newConsumerGroup(group, opts)
+	cg.client = client
+	return cg, nil
+}
+
+func newConsumerGroup(group sarama.ConsumerGroup, opts []ConsumerOption) *ConsumerGroup {
+	cg := &ConsumerGroup{
+		group: group,
+		ready: make(chan struct{}),
+		stop:  make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(cg)
+	}
+	if cg.log == nil {
+		cg.log = logger.CreateLogger(logger.Config{})
+	}
+	return cg
+}
+
+// Run consumes topics until ctx is cancelled or Close is called, calling
+// handler for every message. The session is re-joined after each rebalance.
+// Handlers receive ctx itself, so Close lets in-flight handlers finish while
+// cancelling ctx aborts them.
+//
+// Offsets are marked only after handler succeeds. A failed message is retried
+// per WithRetryPolicy, then sent to the dead-letter topic when WithDLQ is set
+// and marked. Without a DLQ it is logged and skipped; a later success on the
+// same partition commits past it.
+func (cg *ConsumerGroup) Run(ctx context.Context, topics []string, handler Handler) error {
+	if len(topics) == 0 {
+		return errors.New("kafka: at least one topic is required")
+	}
+
+	cg.mu.Lock()
+	if cg.closed {
+		cg.mu.Unlock()
+		return ErrConsumerClosed
+	}
+	cg.running.Add(1)
+	cg.mu.Unlock()
+	defer cg.running.Done()
+
+	// consumeCtx ends the session on Close without cancelling handlers.
+	consumeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-cg.stop:
+			cancel()
+		case <-consumeCtx.Done():
+		}
+	}()
+
+	done := make(chan struct{})
+	defer close(done)
+	go cg.drainErrors(done)
+
+	h := &groupHandler{cg: cg, ctx: ctx, handler: handler}
+	for {
+		err := cg.group.Consume(consumeCtx, topics, h)
+		switch {
+		case errors.Is(err, sarama.ErrClosedConsumerGroup):
+			return nil
+		case err != nil:
+			return fmt.Errorf("kafka: consume %v: %w", topics, err)
+		}
+		if consumeCtx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// Ready is closed once the consumer has joined the group and received its
+// first partition assignment. Use it to gate readiness probes.
+func (cg *ConsumerGroup) Ready() <-chan struct{} {
+	return cg.ready
+}
+
+// Ping reports whether at least one broker connection is alive.
+func (cg *ConsumerGroup) Ping(ctx context.Context) error {
+	return pingClient(ctx, cg.client)
+}
+
+// Close stops claiming new messages, waits for in-flight handlers to finish
+// and their offsets to be committed, then leaves the group. If ctx ends
+// first the group is left anyway and handlers still running may have their
+// messages redelivered. Later calls return the first result.
+func (cg *ConsumerGroup) Close(ctx context.Context) error {
+	cg.closeOnce.Do(func() {
+		cg.mu.Lock()
+		cg.closed = true
+		cg.mu.Unlock()
+		close(cg.stop)
+
+		drained := make(chan struct{})
+		go func() {
+			cg.running.Wait()
+			close(drained)
+		}()
+
+		var errs []error
+		select {
+		case <-drained:
+		case <-ctx.Done():
+			errs = append(errs, fmt.Errorf("kafka: consumer did not drain: %w", ctx.Err()))
+		}
+		if err := cg.group.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("kafka: failed to leave group: %w", err))
+		}
+		if cg.client != nil {
+			if err := cg.client.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("kafka: failed to close client: %w", err))
+			}
+		}
+		cg.closeErr = errors.Join(errs...)
+	})
+	return cg.closeErr
+}
+
+func (cg *ConsumerGroup) drainErrors(done <-chan struct{}) {
+	for {
+		select {
+		case err, ok := <-cg.group.Errors():
+			if !ok {
+				return
+			}
+			cg.log.Error("kafka consumer error", zap.Error(err))
+		case <-done:
+			return
+		}
+	}
+}
+
+// groupHandler adapts a Handler to sarama.ConsumerGroupHandler.
+type groupHandler struct {
+	cg *ConsumerGroup
+	// ctx is passed to handler; the session context only stops the loop.
+	ctx     context.Context
+	handler Handler
+}
+
+func (h *groupHandler) Setup(sarama.ConsumerGroupSession) error {
+	h.cg.readyOnce.Do(func() {
+		close(h.cg.ready)
+	})
+	return nil
+}
+
+func (h *groupHandler) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (h *groupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	ctx := session.Context()
+	for {
+		select {
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
+			attempts, err := h.handleWithRetry(ctx, msg)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				h.cg.log.Error("kafka handler failed",
+					zap.String("topic", msg.Topic),
+					zap.Int32("partition", msg.Partition),
+					zap.Int64("offset", msg.Offset),
+					zap.Int("attempts", attempts),
+					zap.Error(err),
+				)
+				if h.cg.dlq == nil {
+					continue
+				}
+				if !h.cg.dlq.send(ctx, h.cg.log, msg, err, attempts) {
+					return nil
+				}
+			}
+			session.MarkMessage(msg, "")
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// handle calls the handler, turning a panic into an error.
+func (h *groupHandler) handle(ctx context.Context, msg *sarama.ConsumerMessage) (err error) {
+	if h.cg.metrics != nil {
+		start := time.Now()
+		defer func() {
+			h.cg.metrics.ObserveConsume(msg.Topic, metricsOutcome(err), time.Since(start))
+		}()
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			h.cg.log.Error("recovered from panic in kafka handler",
+				zap.Any("panic", recovered),
+				zap.ByteString("stack", debug.Stack()),
+			)
+			err = fmt.Errorf("kafka: handler panic: %v", recovered)
+		}
+	}()
+	return h.handler(ctx, msg)
+}
@@ -0,0 +1,334 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// fakeGroup runs one session per batch sent on sessions. Each session
+// delivers its batch through a fakeClaim and then ends, as a rebalance
+// would; Consume then waits for the next batch or for ctx to end.
+type fakeGroup struct {
+	sarama.ConsumerGroup
+	sessions   chan []*sarama.ConsumerMessage
+	errs       chan error
+	consumeErr error
+
+	calls     atomic.Int32
+	closed    atomic.Bool
+	closeOnce sync.Once
+
+	mu     sync.Mutex
+	joined []*fakeSession
+}
+
+func newFakeGroup() *fakeGroup {
+	return &fakeGroup{
+		sessions: make(chan []*sarama.ConsumerMessage, 8),
+		errs:     make(chan error, 8),
+	}
+}
+
+func (g *fakeGroup) Consume(ctx context.Context, _ []string, h sarama.ConsumerGroupHandler) error {
+	g.calls.Add(1)
+	if g.closed.Load() {
+		return sarama.ErrClosedConsumerGroup
+	}
+	if g.consumeErr != nil {
+		return g.consumeErr
+	}
+
+	var batch []*sarama.ConsumerMessage
+	select {
+	case batch = <-g.sessions:
+	case <-ctx.Done():
+		return nil
+	}
+
+	session := &fakeSession{ctx: ctx}
+	g.mu.Lock()
+	g.joined = append(g.joined, session)
+	g.mu.Unlock()
+
+	if err := h.Setup(session); err != nil {
+		return err
+	}
+	err := h.ConsumeClaim(session, newFakeClaim(batch...))
+	if cleanupErr := h.Cleanup(session); err == nil {
+		err = cleanupErr
+	}
+	return err
+}
+
+func (g *fakeGroup) Errors() <-chan error {
+	return g.errs
+}
+
+func (g *fakeGroup) Close() error {
+	g.closed.Store(true)
+	g.closeOnce.Do(func() { close(g.errs) })
+	return nil
+}
+
+func (g *fakeGroup) markedOffsets() [][]int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var marked [][]int64
+	for _, session := range g.joined {
+		marked = append(marked, session.markedOffsets())
+	}
+	return marked
+}
+
+// runGroup starts cg.Run in the background and returns its result channel.
+func runGroup(ctx context.Context, cg *ConsumerGroup, handler Handler) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		done <- cg.Run(ctx, []string{"orders"}, handler)
+	}()
+	return done
+}
+
+func waitRun(t *testing.T, done <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not return")
+		return nil
+	}
+}
+
+func TestConsumerGroupRunRejoinsAfterRebalance(t *testing.T) {
+	group := newFakeGroup()
+	cg := newConsumerGroup(group, []ConsumerOption{WithConsumerLogger(zap.NewNop())})
+
+	var mu sync.Mutex
+	var handled []int64
+	allHandled := make(chan struct{})
+	handler := func(_ context.Context, msg *sarama.ConsumerMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, msg.Offset)
+		if len(handled) == 3 {
+			close(allHandled)
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := runGroup(ctx, cg, handler)
+
+	group.sessions <- []*sarama.ConsumerMessage{testMessage(1), testMessage(2)}
+	select {
+	case <-cg.Ready():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Ready() not closed after the first session started")
+	}
+	group.sessions <- []*sarama.ConsumerMessage{testMessage(3)}
+
+	select {
+	case <-allHandled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("messages of the second session were not handled")
+	}
+	cancel()
+	if err := waitRun(t, done); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if !slices.Equal(handled, []int64{1, 2, 3}) {
+		t.Errorf("handled offsets = %v, want [1 2 3]", handled)
+	}
+	marked := group.markedOffsets()
+	if len(marked) != 2 || !slices.Equal(marked[0], []int64{1, 2}) || !slices.Equal(marked[1], []int64{3}) {
+		t.Errorf("marked offsets per session = %v, want [[1 2] [3]]", marked)
+	}
+	if calls := group.calls.Load(); calls < 3 {
+		t.Errorf("Consume called %d times, want a re-join after each session", calls)
+	}
+}
+
+func TestConsumerGroupRunDrainsErrors(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	group := newFakeGroup()
+	cg := newConsumerGroup(group, []ConsumerOption{WithConsumerLogger(zap.New(core))})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := runGroup(ctx, cg, func(context.Context, *sarama.ConsumerMessage) error { return nil })
+
+	group.errs <- errors.New("offset commit failed")
+	group.errs <- errors.New("broker gone")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for logs.FilterMessage("kafka consumer error").Len() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("logged %d consumer errors, want 2", logs.FilterMessage("kafka consumer error").Len())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	cancel()
+	if err := waitRun(t, done); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+}
+
+func TestConsumerGroupRunExit(t *testing.T) {
+	noop := func(context.Context, *sarama.ConsumerMessage) error { return nil }
+
+	t.Run("ctx cancelled", func(t *testing.T) {
+		cg := newConsumerGroup(newFakeGroup(), []ConsumerOption{WithConsumerLogger(zap.NewNop())})
+		ctx, cancel := context.WithCancel(context.Background())
+		done := runGroup(ctx, cg, noop)
+		cancel()
+		if err := waitRun(t, done); err != nil {
+			t.Errorf("Run() error = %v, want nil", err)
+		}
+	})
+
+	t.Run("consume error", func(t *testing.T) {
+		group := newFakeGroup()
+		group.consumeErr = sarama.ErrOutOfBrokers
+		cg := newConsumerGroup(group, []ConsumerOption{WithConsumerLogger(zap.NewNop())})
+		err := waitRun(t, runGroup(context.Background(), cg, noop))
+		if !errors.Is(err, sarama.ErrOutOfBrokers) || err.Error() != "kafka: consume [orders]: "+sarama.ErrOutOfBrokers.Error() {
+			t.Errorf("Run() error = %v, want the wrapped Consume error", err)
+		}
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		group := newFakeGroup()
+		cg := newConsumerGroup(group, []ConsumerOption{WithConsumerLogger(zap.NewNop())})
+		done := runGroup(context.Background(), cg, noop)
+		for group.calls.Load() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		if err := cg.Close(context.Background()); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+		if err := waitRun(t, done); err != nil {
+			t.Errorf("Run() error = %v, want nil", err)
+		}
+		if err := cg.Run(context.Background(), []string{"orders"}, noop); !errors.Is(err, ErrConsumerClosed) {
+			t.Errorf("Run() after Close error = %v, want ErrConsumerClosed", err)
+		}
+	})
+
+	t.Run("no topics", func(t *testing.T) {
+		cg := newConsumerGroup(newFakeGroup(), nil)
+		if err := cg.Run(context.Background(), nil, noop); err == nil {
+			t.Error("Run() without topics succeeded")
+		}
+	})
+}
+
+func TestConsumerGroupCloseWaitsForHandlers(t *testing.T) {
+	group := newFakeGroup()
+	cg := newConsumerGroup(group, []ConsumerOption{WithConsumerLogger(zap.NewNop())})
+
+	var afterClose atomic.Bool
+	var lateCalls atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := func(_ context.Context, msg *sarama.ConsumerMessage) error {
+		if afterClose.Load() {
+			lateCalls.Add(1)
+		}
+		if msg.Offset == 0 {
+			close(started)
+			<-release
+		}
+		return nil
+	}
+
+	batch := make([]*sarama.ConsumerMessage, 100)
+	for i := range batch {
+		batch[i] = testMessage(int64(i))
+	}
+	group.sessions <- batch
+	done := runGroup(context.Background(), cg, handler)
+	<-started
+
+	closed := make(chan error, 1)
+	go func() {
+		err := cg.Close(context.Background())
+		afterClose.Store(true)
+		closed <- err
+	}()
+
+	select {
+	case err := <-closed:
+		t.Fatalf("Close() returned %v while a handler was still running", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close() did not return after the handler finished")
+	}
+	if err := waitRun(t, done); err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if n := lateCalls.Load(); n != 0 {
+		t.Errorf("handler ran %d times after Close returned", n)
+	}
+	marked := group.markedOffsets()
+	if len(marked) != 1 || len(marked[0]) == 0 || marked[0][0] != 0 {
+		t.Errorf("marked offsets = %v, want the in-flight message marked", marked)
+	}
+	if !group.closed.Load() {
+		t.Error("group not left on Close")
+	}
+}
+
+func TestConsumerGroupCloseDeadline(t *testing.T) {
+	group := newFakeGroup()
+	cg := newConsumerGroup(group, []ConsumerOption{WithConsumerLogger(zap.NewNop())})
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	handler := func(context.Context, *sarama.ConsumerMessage) error {
+		close(started)
+		<-release
+		return nil
+	}
+
+	group.sessions <- []*sarama.ConsumerMessage{testMessage(1)}
+	runGroup(context.Background(), cg, handler)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := cg.Close(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Close() error = %v, want context.DeadlineExceeded", err)
+	}
+	if !group.closed.Load() {
+		t.Error("group not left after the drain deadline")
+	}
+	if again := cg.Close(context.Background()); again != err {
+		t.Errorf("second Close() error = %v, want the first result %v", again, err)
+	}
+}
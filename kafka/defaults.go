@@ -0,0 +1,49 @@
+package kafka
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+// Producer defaults applied by NewDefaultConfig.
+const (
+	DefaultProducerRetryMax     = 5
+	DefaultProducerRetryBackoff = 250 * time.Millisecond
+)
+
+// NewDefaultConfig returns a Config tuned for durable producing: acks from
+// all in-sync replicas, idempotence with one in-flight request per broker,
+// snappy compression, retries and Return.Successes so NewProducer works.
+// version is a Kafka version such as "3.5.0" and must be at least 0.11.0,
+// the first with idempotent producers. Fields can be changed on the result
+// before use; Address and Group are left empty.
+func NewDefaultConfig(clientID string, version string) (*Config, error) {
+	kafkaVersion, err := sarama.ParseKafkaVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("kafka: invalid version %q: %w", version, err)
+	}
+	if !kafkaVersion.IsAtLeast(sarama.V0_11_0_0) {
+		return nil, fmt.Errorf("kafka: version %s does not support idempotent producers, need 0.11.0 or later", kafkaVersion)
+	}
+
+	config := sarama.NewConfig()
+	if clientID != "" {
+		config.ClientID = clientID
+	}
+	config.Version = kafkaVersion
+	config.Net.MaxOpenRequests = 1
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Idempotent = true
+	config.Producer.Compression = sarama.CompressionSnappy
+	config.Producer.Retry.Max = DefaultProducerRetryMax
+	config.Producer.Retry.Backoff = DefaultProducerRetryBackoff
+	config.Producer.Return.Successes = true
+	config.Producer.Return.Errors = true
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &Config{Config: *config}, nil
+}
@@ -0,0 +1,89 @@
+package kafka
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Shopify/sarama"
+)
+
+func TestNewDefaultConfig(t *testing.T) {
+	cf, err := NewDefaultConfig("orders-service", "3.3.1")
+	if err != nil {
+		t.Fatalf("NewDefaultConfig() error = %v", err)
+	}
+
+	config := cf.Config
+	checks := []struct {
+		name      string
+		got, want any
+	}{
+		{"ClientID", config.ClientID, "orders-service"},
+		{"Version", config.Version, sarama.V3_3_1_0},
+		{"Net.MaxOpenRequests", config.Net.MaxOpenRequests, 1},
+		{"Producer.RequiredAcks", config.Producer.RequiredAcks, sarama.WaitForAll},
+		{"Producer.Idempotent", config.Producer.Idempotent, true},
+		{"Producer.Compression", config.Producer.Compression, sarama.CompressionSnappy},
+		{"Producer.Retry.Max", config.Producer.Retry.Max, DefaultProducerRetryMax},
+		{"Producer.Retry.Backoff", config.Producer.Retry.Backoff, DefaultProducerRetryBackoff},
+		{"Producer.Return.Successes", config.Producer.Return.Successes, true},
+		{"Producer.Return.Errors", config.Producer.Return.Errors, true},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+	if len(cf.Address) != 0 || cf.Group != "" {
+		t.Errorf("Address = %v, Group = %q, want both empty", cf.Address, cf.Group)
+	}
+	if _, err := cf.BuildSaramaConfig(); err != nil {
+		t.Errorf("BuildSaramaConfig() error = %v", err)
+	}
+
+	// Fields stay overridable after construction.
+	cf.Config.Producer.Compression = sarama.CompressionZSTD
+	if _, err := cf.BuildSaramaConfig(); err != nil {
+		t.Errorf("BuildSaramaConfig() after override error = %v", err)
+	}
+}
+
+func TestNewDefaultConfigClientID(t *testing.T) {
+	cf, err := NewDefaultConfig("", "2.8.0")
+	if err != nil {
+		t.Fatalf("NewDefaultConfig() error = %v", err)
+	}
+	if want := sarama.NewConfig().ClientID; cf.Config.ClientID != want {
+		t.Errorf("ClientID = %q, want sarama's default %q", cf.Config.ClientID, want)
+	}
+}
+
+func TestNewDefaultConfigVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		wantErr string
+	}{
+		{"0.11.0.2", ""},
+		{"three", `kafka: invalid version "three": `},
+		{"", `kafka: invalid version "": `},
+		{"0.10.2.1", "kafka: version 0.10.2.1 does not support idempotent producers, need 0.11.0 or later"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			cf, err := NewDefaultConfig("svc", tt.version)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("NewDefaultConfig() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Fatalf("NewDefaultConfig() error = %v, want %q", err, tt.wantErr)
+			}
+			if cf != nil {
+				t.Error("NewDefaultConfig() returned a config with its error")
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+// ErrNoBrokers is returned when no broker can be reached.
+var ErrNoBrokers = errors.New("kafka: no reachable brokers")
+
+type BrokerHealth struct {
+	ID        int32  `json:"id"`
+	Addr      string `json:"addr"`
+	Reachable bool   `json:"reachable"`
+	Error     string `json:"error,omitempty"`
+}
+
+type KafkaHealth struct {
+	Reachable bool `json:"reachable"`
+	// Degraded is set when some, but not all, brokers are unreachable.
+	Degraded            bool           `json:"degraded"`
+	Brokers             []BrokerHealth `json:"brokers"`
+	ControllerID        int32          `json:"controller_id"`
+	ControllerAvailable bool           `json:"controller_available"`
+	ClusterID           string         `json:"cluster_id,omitempty"`
+	Latency             time.Duration  `json:"latency"`
+}
+
+// HealthCheck connects to cf.Address, fetches cluster metadata and dials
+// every advertised broker within timeout. Losing some brokers is reported
+// as Degraded; an error is returned only when none can be reached.
+func HealthCheck(ctx context.Context, cf *Config, timeout time.Duration) (KafkaHealth, error) {
+	config, err := cf.BuildSaramaConfig()
+	if err != nil {
+		return KafkaHealth{}, err
+	}
+	config.Net.DialTimeout = timeout
+	config.Net.ReadTimeout = timeout
+	config.Net.WriteTimeout = timeout
+	config.Metadata.Retry.Max = 0
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan KafkaHealth, 1)
+	errc := make(chan error, 1)
+	go func() {
+		health, err := checkCluster(cf.Address, config)
+		done <- health
+		errc <- err
+	}()
+
+	select {
+	case health := <-done:
+		health.Latency = time.Since(start)
+		return health, <-errc
+	case <-ctx.Done():
+		return KafkaHealth{Latency: time.Since(start)}, fmt.Errorf("%w: %w", ErrNoBrokers, ctx.Err())
+	}
+}
+
+func checkCluster(addrs []string, config *sarama.Config) (KafkaHealth, error) {
+	client, err := sarama.NewClient(addrs, config)
+	if err != nil {
+		return KafkaHealth{}, fmt.Errorf("%w: %w", ErrNoBrokers, err)
+	}
+	defer client.Close()
+
+	var health KafkaHealth
+	var connected *sarama.Broker
+	for _, broker := range client.Brokers() {
+		status := BrokerHealth{ID: broker.ID(), Addr: broker.Addr()}
+		if err := broker.Open(config); err != nil && !errors.Is(err, sarama.ErrAlreadyConnected) {
+			status.Error = err.Error()
+		} else if ok, err := broker.Connected(); ok {
+			status.Reachable = true
+			if connected == nil {
+				connected = broker
+			}
+		} else if err != nil {
+			status.Error = err.Error()
+		}
+		health.Brokers = append(health.Brokers, status)
+	}
+
+	reachable := 0
+	for _, status := range health.Brokers {
+		if status.Reachable {
+			reachable++
+		}
+	}
+	health.Reachable = reachable > 0
+	health.Degraded = reachable > 0 && reachable < len(health.Brokers)
+	if !health.Reachable {
+		return health, ErrNoBrokers
+	}
+
+	if controller, err := client.Controller(); err == nil {
+		health.ControllerID = controller.ID()
+		health.ControllerAvailable = true
+	}
+	if metadata, err := connected.GetMetadata(sarama.NewMetadataRequest(config.Version, nil)); err == nil && metadata.ClusterID != nil {
+		health.ClusterID = *metadata.ClusterID
+	}
+	return health, nil
+}
+
+// pingClient succeeds if client has a live broker connection. When it has
+// none, as on a client that has not sent anything yet since sarama opens
+// brokers lazily, it refreshes metadata and opens the known brokers once.
+func pingClient(ctx context.Context, client sarama.Client) error {
+	if client == nil {
+		return errors.New("kafka: no client to ping")
+	}
+	if client.Closed() {
+		return errors.New("kafka: client is closed")
+	}
+	if hasConnectedBroker(client) {
+		return nil
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		if err := client.RefreshMetadata(); err != nil {
+			done <- fmt.Errorf("%w: %w", ErrNoBrokers, err)
+			return
+		}
+		// Open errors, including ErrAlreadyConnected, show up in Connected.
+		for _, broker := range client.Brokers() {
+			_ = broker.Open(client.Config())
+		}
+		if !hasConnectedBroker(client) {
+			done <- ErrNoBrokers
+			return
+		}
+		done <- nil
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func hasConnectedBroker(client sarama.Client) bool {
+	for _, broker := range client.Brokers() {
+		if ok, _ := broker.Connected(); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+// newHealthCluster serves metadata listing every broker in brokers plus
+// deadAddrs, which nothing listens on. The first broker is the controller.
+func newHealthCluster(t *testing.T, brokers []*sarama.MockBroker, deadAddrs ...string) *Config {
+	t.Helper()
+	metadata := sarama.NewMockMetadataResponse(t).SetController(brokers[0].BrokerID())
+	for _, broker := range brokers {
+		metadata.SetBroker(broker.Addr(), broker.BrokerID())
+	}
+	for i, addr := range deadAddrs {
+		metadata.SetBroker(addr, int32(100+i))
+	}
+	for _, broker := range brokers {
+		broker.SetHandlerByMap(map[string]sarama.MockResponse{"MetadataRequest": metadata})
+	}
+
+	config := sarama.NewConfig()
+	config.Version = sarama.V1_0_0_0
+	return &Config{Address: []string{brokers[0].Addr()}, Config: *config}
+}
+
+func newMockBrokers(t *testing.T, n int) []*sarama.MockBroker {
+	t.Helper()
+	brokers := make([]*sarama.MockBroker, n)
+	for i := range brokers {
+		brokers[i] = sarama.NewMockBroker(t, int32(i+1))
+		t.Cleanup(brokers[i].Close)
+	}
+	return brokers
+}
+
+// deadAddr returns a local address that refuses connections.
+func deadAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+// silentAddr returns an address that accepts connections but never
+// answers, so requests only end on a timeout.
+func silentAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var conns []net.Conn
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				for _, conn := range conns {
+					conn.Close()
+				}
+				return
+			}
+			conns = append(conns, conn)
+		}
+	}()
+	t.Cleanup(func() {
+		ln.Close()
+		<-done
+	})
+	return ln.Addr().String()
+}
+
+func TestHealthCheckHealthy(t *testing.T) {
+	brokers := newMockBrokers(t, 2)
+	cf := newHealthCluster(t, brokers)
+
+	health, err := HealthCheck(context.Background(), cf, 5*time.Second)
+	if err != nil {
+		t.Fatalf("HealthCheck() error = %v", err)
+	}
+	if !health.Reachable || health.Degraded {
+		t.Errorf("Reachable = %v, Degraded = %v, want a healthy cluster", health.Reachable, health.Degraded)
+	}
+	if !health.ControllerAvailable || health.ControllerID != 1 {
+		t.Errorf("controller = %d (available %v), want broker 1", health.ControllerID, health.ControllerAvailable)
+	}
+	if len(health.Brokers) != 2 {
+		t.Fatalf("Brokers = %+v, want 2", health.Brokers)
+	}
+	for _, broker := range health.Brokers {
+		if !broker.Reachable || broker.Error != "" {
+			t.Errorf("broker %d = %+v, want reachable", broker.ID, broker)
+		}
+	}
+	if health.Latency <= 0 {
+		t.Errorf("Latency = %v, want it measured", health.Latency)
+	}
+}
+
+func TestHealthCheckDegraded(t *testing.T) {
+	brokers := newMockBrokers(t, 1)
+	dead := deadAddr(t)
+	cf := newHealthCluster(t, brokers, dead)
+
+	health, err := HealthCheck(context.Background(), cf, 5*time.Second)
+	if err != nil {
+		t.Fatalf("HealthCheck() error = %v, want partial reachability reported without an error", err)
+	}
+	if !health.Reachable || !health.Degraded {
+		t.Errorf("Reachable = %v, Degraded = %v, want a degraded cluster", health.Reachable, health.Degraded)
+	}
+
+	byAddr := make(map[string]BrokerHealth)
+	for _, broker := range health.Brokers {
+		byAddr[broker.Addr] = broker
+	}
+	if broker := byAddr[brokers[0].Addr()]; !broker.Reachable {
+		t.Errorf("live broker = %+v, want reachable", broker)
+	}
+	if broker := byAddr[dead]; broker.Reachable || broker.Error == "" {
+		t.Errorf("dead broker = %+v, want unreachable with an error", broker)
+	}
+}
+
+func TestHealthCheckUnreachable(t *testing.T) {
+	t.Run("refused", func(t *testing.T) {
+		cf := &Config{Address: []string{deadAddr(t)}, Config: *sarama.NewConfig()}
+		health, err := HealthCheck(context.Background(), cf, 5*time.Second)
+		if !errors.Is(err, ErrNoBrokers) {
+			t.Fatalf("HealthCheck() error = %v, want ErrNoBrokers", err)
+		}
+		if health.Reachable {
+			t.Error("Reachable = true for a refused address")
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		cf := &Config{Address: []string{silentAddr(t)}, Config: *sarama.NewConfig()}
+		start := time.Now()
+		_, err := HealthCheck(context.Background(), cf, 100*time.Millisecond)
+		if !errors.Is(err, ErrNoBrokers) {
+			t.Fatalf("HealthCheck() error = %v, want ErrNoBrokers", err)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("HealthCheck() took %v, want it bounded by the timeout", elapsed)
+		}
+	})
+}
+
+func TestPingClient(t *testing.T) {
+	brokers := newMockBrokers(t, 1)
+	cf := newHealthCluster(t, brokers)
+	config, err := cf.BuildSaramaConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := sarama.NewClient(cf.Address, config)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	if err := pingClient(context.Background(), client); err != nil {
+		t.Errorf("pingClient() error = %v", err)
+	}
+	client.Close()
+	if err := pingClient(context.Background(), client); err == nil || err.Error() != "kafka: client is closed" {
+		t.Errorf("pingClient() after Close error = %v", err)
+	}
+	if err := pingClient(context.Background(), nil); err == nil {
+		t.Error("pingClient(nil) succeeded")
+	}
+}
@@ -1,19 +1,30 @@
 package kafka
 
-import "github.com/Shopify/sarama"
+import (
+	"fmt"
+
+	"github.com/Shopify/sarama"
+)
 
 type Config struct {
 	Address []string
 	Config  sarama.Config
 	Group   string
+
+	TLS  TLSConfig
+	SASL SASLConfig
 }
 
 // func (cf *Config)Init
 
 func (cf *Config) NewProducer() (sarama.SyncProducer, error) {
-	producer, err := sarama.NewSyncProducer(cf.Address, &cf.Config)
+	config, err := cf.BuildSaramaConfig()
 	if err != nil {
 		return nil, err
 	}
+	producer, err := sarama.NewSyncProducer(cf.Address, config)
+	if err != nil {
+		return nil, fmt.Errorf("kafka: failed to create producer for %v: %w", cf.Address, err)
+	}
 	return producer, nil
 }
@@ -0,0 +1,162 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"go.uber.org/zap"
+)
+
+// PartitionLag is the lag of one partition for a consumer group.
+type PartitionLag struct {
+	Lag int64
+	// Committed is false when the group has no committed offset for the
+	// partition; Lag is then measured from the oldest available offset.
+	Committed bool
+}
+
+// LagRecorder receives lag values from StartLagReporter. Implementations
+// must be safe for concurrent use.
+type LagRecorder interface {
+	SetLag(group, topic string, partition int32, lag int64, committed bool)
+}
+
+// GroupLag returns the lag of group per topic and partition: the newest
+// offset minus the group's committed offset.
+func GroupLag(ctx context.Context, cf *Config, group string, topics []string) (map[string]map[int32]int64, error) {
+	details, err := GroupLagDetails(ctx, cf, group, topics)
+	if err != nil {
+		return nil, err
+	}
+
+	lags := make(map[string]map[int32]int64, len(details))
+	for topic, partitions := range details {
+		lags[topic] = make(map[int32]int64, len(partitions))
+		for partition, lag := range partitions {
+			lags[topic][partition] = lag.Lag
+		}
+	}
+	return lags, nil
+}
+
+// GroupLagDetails is GroupLag with a flag for partitions the group has not
+// committed yet.
+func GroupLagDetails(ctx context.Context, cf *Config, group string, topics []string) (map[string]map[int32]PartitionLag, error) {
+	return withClient(ctx, cf, func(client sarama.Client, admin sarama.ClusterAdmin) (map[string]map[int32]PartitionLag, error) {
+		return groupLag(client, admin, group, topics)
+	})
+}
+
+// StartLagReporter reports the lag of group to rec every interval until ctx
+// is done, reusing one connection. The connection is made in the
+// background; connect and fetch failures are logged to log when it is not
+// nil and retried on the next tick. interval must be positive and rec
+// non-nil.
+func StartLagReporter(ctx context.Context, cf *Config, group string, topics []string, interval time.Duration, rec LagRecorder, log *zap.Logger) error {
+	if interval <= 0 {
+		return fmt.Errorf("kafka: lag report interval must be positive, got %s", interval)
+	}
+	if rec == nil {
+		return errors.New("kafka: lag recorder is required")
+	}
+	if _, err := cf.BuildSaramaConfig(); err != nil {
+		return err
+	}
+
+	go func() {
+		var client sarama.Client
+		var admin sarama.ClusterAdmin
+		defer func() {
+			if admin != nil {
+				admin.Close()
+			}
+		}()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if admin == nil {
+					var err error
+					if client, admin, err = newAdmin(cf); err != nil {
+						if log != nil {
+							log.Warn("failed to connect for kafka consumer lag", zap.String("group", group), zap.Error(err))
+						}
+						continue
+					}
+				}
+				lags, err := groupLag(client, admin, group, topics)
+				if err != nil {
+					if log != nil {
+						log.Warn("failed to fetch kafka consumer lag", zap.String("group", group), zap.Error(err))
+					}
+					continue
+				}
+				for topic, partitions := range lags {
+					for partition, lag := range partitions {
+						rec.SetLag(group, topic, partition, lag.Lag, lag.Committed)
+					}
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func groupLag(client sarama.Client, admin sarama.ClusterAdmin, group string, topics []string) (map[string]map[int32]PartitionLag, error) {
+	partitions := make(map[string][]int32, len(topics))
+	for _, topic := range topics {
+		ids, err := client.Partitions(topic)
+		if err != nil {
+			return nil, fmt.Errorf("kafka: failed to list partitions of %s: %w", topic, err)
+		}
+		partitions[topic] = ids
+	}
+
+	committed, err := admin.ListConsumerGroupOffsets(group, partitions)
+	if err != nil {
+		return nil, fmt.Errorf("kafka: failed to fetch offsets of group %s: %w", group, err)
+	}
+	if committed.Err != sarama.ErrNoError {
+		return nil, fmt.Errorf("kafka: failed to fetch offsets of group %s: %w", group, committed.Err)
+	}
+
+	lags := make(map[string]map[int32]PartitionLag, len(partitions))
+	for topic, ids := range partitions {
+		lags[topic] = make(map[int32]PartitionLag, len(ids))
+		for _, partition := range ids {
+			newest, err := client.GetOffset(topic, partition, sarama.OffsetNewest)
+			if err != nil {
+				return nil, fmt.Errorf("kafka: failed to fetch newest offset of %s/%d: %w", topic, partition, err)
+			}
+
+			offset := int64(-1)
+			if block := committed.GetBlock(topic, partition); block != nil && block.Err == sarama.ErrNoError {
+				offset = block.Offset
+			}
+			lag := PartitionLag{Committed: offset >= 0}
+			if !lag.Committed {
+				offset, err = client.GetOffset(topic, partition, sarama.OffsetOldest)
+				if err != nil {
+					return nil, fmt.Errorf("kafka: failed to fetch oldest offset of %s/%d: %w", topic, partition, err)
+				}
+			}
+			lag.Lag = lagBetween(newest, offset)
+			lags[topic][partition] = lag
+		}
+	}
+	return lags, nil
+}
+
+func lagBetween(newest, offset int64) int64 {
+	if offset >= newest {
+		return 0
+	}
+	return newest - offset
+}
@@ -0,0 +1,161 @@
+package kafka
+
+import (
+	"context"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+type nopLagRecorder struct{}
+
+func (nopLagRecorder) SetLag(string, string, int32, int64, bool) {}
+
+func TestStartLagReporterValidation(t *testing.T) {
+	// The address is never dialled: validation fails first.
+	cf := &Config{Address: []string{"127.0.0.1:1"}}
+
+	tests := []struct {
+		name     string
+		interval time.Duration
+		rec      LagRecorder
+		wantErr  string
+	}{
+		{"zero interval", 0, nopLagRecorder{}, "kafka: lag report interval must be positive, got 0s"},
+		{"negative interval", -time.Second, nopLagRecorder{}, "kafka: lag report interval must be positive, got -1s"},
+		{"nil recorder", time.Second, nil, "kafka: lag recorder is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := StartLagReporter(context.Background(), cf, "group", []string{"orders"}, tt.interval, tt.rec, nil)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("StartLagReporter() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func newLagCluster(t *testing.T) *Config {
+	t.Helper()
+	broker := sarama.NewMockBroker(t, 1)
+	t.Cleanup(broker.Close)
+
+	metadata := sarama.NewMockMetadataResponse(t).
+		SetController(broker.BrokerID()).
+		SetBroker(broker.Addr(), broker.BrokerID())
+	for partition := int32(0); partition < 4; partition++ {
+		metadata.SetLeader("orders", partition, broker.BrokerID())
+	}
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": metadata,
+		"FindCoordinatorRequest": sarama.NewMockFindCoordinatorResponse(t).
+			SetCoordinator(sarama.CoordinatorGroup, "billing", broker),
+		// Partition 2 has no block at all; partition 1 reports -1, which is
+		// what Kafka returns for a partition the group never committed.
+		"OffsetFetchRequest": sarama.NewMockOffsetFetchResponse(t).
+			SetOffset("billing", "orders", 0, 40, "", sarama.ErrNoError).
+			SetOffset("billing", "orders", 1, -1, "", sarama.ErrNoError).
+			SetOffset("billing", "orders", 3, 120, "", sarama.ErrNoError),
+		"OffsetRequest": sarama.NewMockOffsetResponse(t).
+			SetOffset("orders", 0, sarama.OffsetNewest, 100).
+			SetOffset("orders", 0, sarama.OffsetOldest, 0).
+			SetOffset("orders", 1, sarama.OffsetNewest, 50).
+			SetOffset("orders", 1, sarama.OffsetOldest, 10).
+			SetOffset("orders", 2, sarama.OffsetNewest, 5).
+			SetOffset("orders", 2, sarama.OffsetOldest, 5).
+			SetOffset("orders", 3, sarama.OffsetNewest, 100).
+			SetOffset("orders", 3, sarama.OffsetOldest, 0),
+	})
+
+	config := sarama.NewConfig()
+	config.Version = sarama.V1_0_0_0
+	config.Metadata.Retry.Max = 0
+	return &Config{Address: []string{broker.Addr()}, Config: *config}
+}
+
+var wantLag = map[int32]PartitionLag{
+	0: {Lag: 60, Committed: true},
+	1: {Lag: 40, Committed: false},
+	2: {Lag: 0, Committed: false},
+	// A committed offset past the newest one is clamped to zero.
+	3: {Lag: 0, Committed: true},
+}
+
+func TestGroupLagDetailsMockBroker(t *testing.T) {
+	cf := newLagCluster(t)
+
+	details, err := GroupLagDetails(context.Background(), cf, "billing", []string{"orders"})
+	if err != nil {
+		t.Fatalf("GroupLagDetails() error = %v", err)
+	}
+	if !maps.Equal(details["orders"], wantLag) {
+		t.Errorf("GroupLagDetails()[orders] = %v, want %v", details["orders"], wantLag)
+	}
+
+	lags, err := GroupLag(context.Background(), cf, "billing", []string{"orders"})
+	if err != nil {
+		t.Fatalf("GroupLag() error = %v", err)
+	}
+	want := map[int32]int64{0: 60, 1: 40, 2: 0, 3: 0}
+	if !maps.Equal(lags["orders"], want) {
+		t.Errorf("GroupLag()[orders] = %v, want %v", lags["orders"], want)
+	}
+}
+
+type lagSample struct {
+	partition int32
+	lag       PartitionLag
+}
+
+type chanLagRecorder chan lagSample
+
+func (c chanLagRecorder) SetLag(group, topic string, partition int32, lag int64, committed bool) {
+	c <- lagSample{partition, PartitionLag{Lag: lag, Committed: committed}}
+}
+
+func TestStartLagReporterMockBroker(t *testing.T) {
+	cf := newLagCluster(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rec := make(chanLagRecorder, 64)
+	if err := StartLagReporter(ctx, cf, "billing", []string{"orders"}, 10*time.Millisecond, rec, nil); err != nil {
+		t.Fatalf("StartLagReporter() error = %v", err)
+	}
+
+	got := make(map[int32]PartitionLag)
+	timeout := time.After(5 * time.Second)
+	for len(got) < len(wantLag) {
+		select {
+		case s := <-rec:
+			got[s.partition] = s.lag
+		case <-timeout:
+			t.Fatalf("recorded %v before timeout, want %v", got, wantLag)
+		}
+	}
+	if !maps.Equal(got, wantLag) {
+		t.Errorf("recorded %v, want %v", got, wantLag)
+	}
+}
+
+func TestStartLagReporterDoesNotBlockOnConnect(t *testing.T) {
+	// A non-routable address would hang a synchronous connect until the
+	// dial timeout; the reporter connects in the background instead.
+	config := sarama.NewConfig()
+	config.Net.DialTimeout = 30 * time.Second
+	cf := &Config{Address: []string{"10.255.255.1:9092"}, Config: *config}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	start := time.Now()
+	if err := StartLagReporter(ctx, cf, "billing", []string{"orders"}, time.Hour, nopLagRecorder{}, nil); err != nil {
+		t.Fatalf("StartLagReporter() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("StartLagReporter() took %s, want it to return immediately", elapsed)
+	}
+}
@@ -0,0 +1,102 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rcrowley/go-metrics"
+)
+
+// Outcome labels passed to MetricsRecorder.
+const (
+	outcomeOK    = "ok"
+	outcomeError = "error"
+)
+
+// MetricsRecorder receives producer and consumer metrics. Implementations
+// must be safe for concurrent use.
+type MetricsRecorder interface {
+	// ObservePublish records one publish call carrying messages messages.
+	// outcome is "ok" or "error".
+	ObservePublish(topic, outcome string, messages int, d time.Duration)
+	// ObserveConsume records one handler attempt. outcome is "ok" or
+	// "error".
+	ObserveConsume(topic, outcome string, d time.Duration)
+	// SetClientMetric records a value exported from sarama's metrics
+	// registry, such as "request-latency-in-ms.mean".
+	SetClientMetric(name string, value float64)
+}
+
+// WithPublishMetrics records the publish to m.
+func WithPublishMetrics(m MetricsRecorder) PublishOption {
+	return func(o *publishOptions) {
+		o.metrics = m
+	}
+}
+
+// WithConsumerMetrics records every handler attempt to m.
+func WithConsumerMetrics(m MetricsRecorder) ConsumerOption {
+	return func(cg *ConsumerGroup) {
+		cg.metrics = m
+	}
+}
+
+func metricsOutcome(err error) string {
+	if err != nil {
+		return outcomeError
+	}
+	return outcomeOK
+}
+
+// ReportSaramaMetrics copies sarama's internal metrics from registry, usually
+// Config.Config.MetricRegistry, into m every interval until ctx is done.
+// Meters report their one-minute rate and count, histograms their mean and
+// 99th percentile, counters and gauges their value.
+//
+// It returns an error straight away when registry or m is nil or interval
+// is not positive, and nil once ctx is done.
+func ReportSaramaMetrics(ctx context.Context, registry metrics.Registry, interval time.Duration, m MetricsRecorder) error {
+	if registry == nil {
+		return errors.New("kafka: metrics registry is required")
+	}
+	if m == nil {
+		return errors.New("kafka: metrics recorder is required")
+	}
+	if interval <= 0 {
+		return fmt.Errorf("kafka: metrics report interval must be positive, got %s", interval)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			exportRegistry(registry, m)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func exportRegistry(registry metrics.Registry, m MetricsRecorder) {
+	registry.Each(func(name string, metric interface{}) {
+		switch metric := metric.(type) {
+		case metrics.Meter:
+			snapshot := metric.Snapshot()
+			m.SetClientMetric(name+".rate1", snapshot.Rate1())
+			m.SetClientMetric(name+".count", float64(snapshot.Count()))
+		case metrics.Histogram:
+			snapshot := metric.Snapshot()
+			m.SetClientMetric(name+".mean", snapshot.Mean())
+			m.SetClientMetric(name+".p99", snapshot.Percentile(0.99))
+		case metrics.Counter:
+			m.SetClientMetric(name, float64(metric.Count()))
+		case metrics.Gauge:
+			m.SetClientMetric(name, float64(metric.Value()))
+		case metrics.GaugeFloat64:
+			m.SetClientMetric(name, metric.Value())
+		}
+	})
+}
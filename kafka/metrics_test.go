@@ -0,0 +1,201 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/rcrowley/go-metrics"
+)
+
+type recordedMetrics struct {
+	mu     sync.Mutex
+	client map[string]float64
+}
+
+func (r *recordedMetrics) ObservePublish(string, string, int, time.Duration) {}
+func (r *recordedMetrics) ObserveConsume(string, string, time.Duration)      {}
+func (r *recordedMetrics) SetClientMetric(name string, value float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.client == nil {
+		r.client = make(map[string]float64)
+	}
+	r.client[name] = value
+}
+
+func (r *recordedMetrics) get(name string) (float64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	value, ok := r.client[name]
+	return value, ok
+}
+
+func TestReportSaramaMetricsValidation(t *testing.T) {
+	registry := metrics.NewRegistry()
+	rec := &recordedMetrics{}
+
+	tests := []struct {
+		name     string
+		registry metrics.Registry
+		interval time.Duration
+		m        MetricsRecorder
+		wantErr  string
+	}{
+		{"nil registry", nil, time.Second, rec, "kafka: metrics registry is required"},
+		{"nil recorder", registry, time.Second, nil, "kafka: metrics recorder is required"},
+		{"zero interval", registry, 0, rec, "kafka: metrics report interval must be positive, got 0s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ReportSaramaMetrics(context.Background(), tt.registry, tt.interval, tt.m)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("ReportSaramaMetrics() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReportSaramaMetrics(t *testing.T) {
+	registry := metrics.NewRegistry()
+	metrics.GetOrRegisterCounter("requests", registry).Inc(3)
+	metrics.GetOrRegisterGauge("in-flight", registry).Update(2)
+	histogram := metrics.GetOrRegisterHistogram("latency", registry, metrics.NewUniformSample(10))
+	histogram.Update(10)
+	histogram.Update(20)
+
+	rec := &recordedMetrics{}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- ReportSaramaMetrics(ctx, registry, time.Millisecond, rec)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, ok := rec.get("latency.p99"); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("metrics were not reported")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("ReportSaramaMetrics() error = %v", err)
+	}
+
+	for name, want := range map[string]float64{
+		"requests":     3,
+		"in-flight":    2,
+		"latency.mean": 15,
+		"latency.p99":  20,
+	} {
+		if got, _ := rec.get(name); got != want {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+}
+
+type observation struct {
+	topic    string
+	outcome  string
+	messages int
+}
+
+// observedMetrics records publish and consume observations.
+type observedMetrics struct {
+	mu       sync.Mutex
+	publish  []observation
+	consumed []observation
+}
+
+func (o *observedMetrics) ObservePublish(topic, outcome string, messages int, _ time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.publish = append(o.publish, observation{topic, outcome, messages})
+}
+
+func (o *observedMetrics) ObserveConsume(topic, outcome string, _ time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.consumed = append(o.consumed, observation{topic: topic, outcome: outcome})
+}
+
+func (o *observedMetrics) SetClientMetric(string, float64) {}
+
+func TestWithPublishMetrics(t *testing.T) {
+	ctx := context.Background()
+	producer := newMockSyncProducer(t)
+	producer.ExpectSendMessageAndSucceed()
+	producer.ExpectSendMessageAndFail(sarama.ErrLeaderNotAvailable)
+	producer.ExpectSendMessageAndSucceed()
+	producer.ExpectSendMessageAndSucceed()
+
+	m := &observedMetrics{}
+	if _, _, err := Publish(ctx, producer, "orders", "k", "v", nil, WithPublishMetrics(m)); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if _, _, err := PublishProto(ctx, producer, "payments", "k", []byte("v"), nil, WithPublishMetrics(m)); err == nil {
+		t.Fatal("PublishProto() succeeded, want the mocked failure")
+	}
+	items := []BatchItem{{Key: "a", Value: 1}, {Key: "b", Value: 2}}
+	if _, err := PublishBatch(ctx, producer, "events", items, WithPublishMetrics(m)); err != nil {
+		t.Fatalf("PublishBatch() error = %v", err)
+	}
+	// Messages rejected before sending are not a publish call.
+	if _, _, err := PublishProto(ctx, producer, "orders", "k", make([]byte, 10), nil, WithPublishMetrics(m), WithMaxMessageBytes(5)); !errors.Is(err, ErrMessageTooLarge) {
+		t.Fatalf("PublishProto() error = %v, want ErrMessageTooLarge", err)
+	}
+
+	want := []observation{
+		{"orders", "ok", 1},
+		{"payments", "error", 1},
+		{"events", "ok", 2},
+	}
+	if len(m.publish) != len(want) {
+		t.Fatalf("publish observations = %v, want %v", m.publish, want)
+	}
+	for i := range want {
+		if m.publish[i] != want[i] {
+			t.Errorf("publish observation %d = %v, want %v", i, m.publish[i], want[i])
+		}
+	}
+}
+
+func TestWithConsumerMetrics(t *testing.T) {
+	m := &observedMetrics{}
+	failOnce := map[int64]bool{2: true}
+	handler := func(_ context.Context, msg *sarama.ConsumerMessage) error {
+		switch {
+		case msg.Offset == 3:
+			panic("boom")
+		case failOnce[msg.Offset]:
+			delete(failOnce, msg.Offset)
+			return errors.New("transient")
+		}
+		return nil
+	}
+
+	opts := []ConsumerOption{
+		WithConsumerMetrics(m),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond}),
+	}
+	consume(t, context.Background(), handler, opts, testMessage(1), testMessage(2), testMessage(3))
+
+	// Every attempt is recorded: 1 ok, 2 fails then succeeds, 3 panics twice.
+	want := []string{"ok", "error", "ok", "error", "error"}
+	if len(m.consumed) != len(want) {
+		t.Fatalf("consume observations = %v, want outcomes %v", m.consumed, want)
+	}
+	for i, outcome := range want {
+		if got := m.consumed[i]; got.topic != "orders" || got.outcome != outcome {
+			t.Errorf("consume observation %d = %+v, want orders/%s", i, got, outcome)
+		}
+	}
+}
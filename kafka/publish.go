@@ -0,0 +1,108 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+// DefaultMaxMessageBytes matches sarama's default Producer.MaxMessageBytes.
+const DefaultMaxMessageBytes = 1000000
+
+// ErrMessageTooLarge is returned when a message exceeds the publish size
+// limit.
+var ErrMessageTooLarge = errors.New("kafka: message too large")
+
+type publishOptions struct {
+	maxMessageBytes int
+	maxBatchBytes   int
+	metrics         MetricsRecorder
+}
+
+type PublishOption func(*publishOptions)
+
+// WithMaxMessageBytes sets the size limit checked before sending. Use the
+// producer's Producer.MaxMessageBytes when it differs from the default.
+func WithMaxMessageBytes(n int) PublishOption {
+	return func(o *publishOptions) {
+		o.maxMessageBytes = n
+	}
+}
+
+// Publish marshals value as JSON and sends it to topic. A non-empty key is
+// set so messages with the same key land on the same partition. Headers are
+// sent as record headers.
+func Publish(ctx context.Context, producer sarama.SyncProducer, topic string, key string, value any, headers map[string]string, opts ...PublishOption) (partition int32, offset int64, err error) {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("kafka: failed to marshal message for %s: %w", topic, err)
+	}
+	return PublishProto(ctx, producer, topic, key, payload, headers, opts...)
+}
+
+// PublishProto sends a pre-encoded payload, such as a protobuf message, with
+// the same key, header and size handling as Publish.
+func PublishProto(ctx context.Context, producer sarama.SyncProducer, topic string, key string, payload []byte, headers map[string]string, opts ...PublishOption) (partition int32, offset int64, err error) {
+	options := newPublishOptions(opts)
+	msg, err := newProducerMessage(topic, key, payload, headers, options)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	start := time.Now()
+	partition, offset, err = producer.SendMessage(msg)
+	if options.metrics != nil {
+		options.metrics.ObservePublish(topic, metricsOutcome(err), 1, time.Since(start))
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("kafka: failed to publish to %s: %w", topic, err)
+	}
+	return partition, offset, nil
+}
+
+func newPublishOptions(opts []PublishOption) publishOptions {
+	options := publishOptions{
+		maxMessageBytes: DefaultMaxMessageBytes,
+		maxBatchBytes:   DefaultMaxMessageBytes,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+func newProducerMessage(topic string, key string, payload []byte, headers map[string]string, options publishOptions) (*sarama.ProducerMessage, error) {
+	if topic == "" {
+		return nil, errors.New("kafka: topic is required")
+	}
+
+	size := len(key) + len(payload)
+	for name, value := range headers {
+		size += len(name) + len(value)
+	}
+	if options.maxMessageBytes > 0 && size > options.maxMessageBytes {
+		return nil, fmt.Errorf("%w: %d bytes exceeds %d for %s", ErrMessageTooLarge, size, options.maxMessageBytes, topic)
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic: topic,
+		Value: sarama.ByteEncoder(payload),
+	}
+	if key != "" {
+		msg.Key = sarama.ByteEncoder(key)
+	}
+	if len(headers) > 0 {
+		msg.Headers = make([]sarama.RecordHeader, 0, len(headers))
+		for name, value := range headers {
+			msg.Headers = append(msg.Headers, sarama.RecordHeader{Key: []byte(name), Value: []byte(value)})
+		}
+	}
+	return msg, nil
+}
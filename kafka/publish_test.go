@@ -0,0 +1,152 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+)
+
+func newMockSyncProducer(t *testing.T) *mocks.SyncProducer {
+	config := mocks.NewTestConfig()
+	config.Producer.Return.Successes = true
+	producer := mocks.NewSyncProducer(t, config)
+	t.Cleanup(func() { producer.Close() })
+	return producer
+}
+
+func encoded(t *testing.T, e sarama.Encoder) string {
+	t.Helper()
+	if e == nil {
+		return ""
+	}
+	b, err := e.Encode()
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	return string(b)
+}
+
+func headerMap(headers []sarama.RecordHeader) map[string]string {
+	m := make(map[string]string, len(headers))
+	for _, h := range headers {
+		m[string(h.Key)] = string(h.Value)
+	}
+	return m
+}
+
+func TestPublish(t *testing.T) {
+	producer := newMockSyncProducer(t)
+	var sent *sarama.ProducerMessage
+	producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		sent = msg
+		return nil
+	})
+
+	type order struct {
+		ID    int    `json:"id"`
+		Owner string `json:"owner"`
+	}
+	headers := map[string]string{"content-type": "application/json", "x-request-id": "r-1"}
+	_, _, err := Publish(context.Background(), producer, "orders", "customer-7", order{ID: 42, Owner: "ann"}, headers)
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	if sent.Topic != "orders" {
+		t.Errorf("topic = %q, want orders", sent.Topic)
+	}
+	if got := encoded(t, sent.Key); got != "customer-7" {
+		t.Errorf("key = %q, want customer-7", got)
+	}
+	if got := encoded(t, sent.Value); got != `{"id":42,"owner":"ann"}` {
+		t.Errorf("value = %s, want the JSON encoding", got)
+	}
+	if got := headerMap(sent.Headers); len(got) != 2 || got["content-type"] != "application/json" || got["x-request-id"] != "r-1" {
+		t.Errorf("headers = %v, want %v", got, headers)
+	}
+}
+
+func TestPublishProto(t *testing.T) {
+	producer := newMockSyncProducer(t)
+	var sent *sarama.ProducerMessage
+	producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		sent = msg
+		return nil
+	})
+
+	payload := []byte{0x08, 0x2a, 0x12, 0x03, 'a', 'n', 'n'}
+	if _, _, err := PublishProto(context.Background(), producer, "orders", "", payload, nil); err != nil {
+		t.Fatalf("PublishProto() error = %v", err)
+	}
+	if got := encoded(t, sent.Value); got != string(payload) {
+		t.Errorf("value = %x, want the payload unchanged %x", got, payload)
+	}
+	if sent.Key != nil {
+		t.Errorf("key = %v, want none for an empty key", sent.Key)
+	}
+	if sent.Headers != nil {
+		t.Errorf("headers = %v, want none", sent.Headers)
+	}
+}
+
+func TestPublishErrors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("message too large", func(t *testing.T) {
+		// No expectation is set: the mock fails the test if anything is sent.
+		producer := newMockSyncProducer(t)
+		payload := []byte(strings.Repeat("x", 90))
+		_, _, err := PublishProto(ctx, producer, "orders", "key", payload, map[string]string{"h": "v"}, WithMaxMessageBytes(94))
+		if !errors.Is(err, ErrMessageTooLarge) {
+			t.Fatalf("PublishProto() error = %v, want ErrMessageTooLarge", err)
+		}
+		if want := "kafka: message too large: 95 bytes exceeds 94 for orders"; err.Error() != want {
+			t.Errorf("PublishProto() error = %q, want %q", err, want)
+		}
+	})
+
+	t.Run("size at the limit", func(t *testing.T) {
+		producer := newMockSyncProducer(t)
+		producer.ExpectSendMessageAndSucceed()
+		payload := []byte(strings.Repeat("x", 90))
+		if _, _, err := PublishProto(ctx, producer, "orders", "key", payload, map[string]string{"h": "v"}, WithMaxMessageBytes(95)); err != nil {
+			t.Fatalf("PublishProto() error = %v", err)
+		}
+	})
+
+	t.Run("empty topic", func(t *testing.T) {
+		producer := newMockSyncProducer(t)
+		if _, _, err := Publish(ctx, producer, "", "key", "v", nil); err == nil || err.Error() != "kafka: topic is required" {
+			t.Fatalf("Publish() error = %v, want kafka: topic is required", err)
+		}
+	})
+
+	t.Run("unmarshalable value", func(t *testing.T) {
+		producer := newMockSyncProducer(t)
+		if _, _, err := Publish(ctx, producer, "orders", "key", make(chan int), nil); err == nil {
+			t.Fatal("Publish() of a channel succeeded")
+		}
+	})
+
+	t.Run("cancelled ctx", func(t *testing.T) {
+		producer := newMockSyncProducer(t)
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		if _, _, err := Publish(cancelled, producer, "orders", "key", "v", nil); !errors.Is(err, context.Canceled) {
+			t.Fatalf("Publish() error = %v, want context.Canceled", err)
+		}
+	})
+
+	t.Run("send failure", func(t *testing.T) {
+		producer := newMockSyncProducer(t)
+		producer.ExpectSendMessageAndFail(sarama.ErrLeaderNotAvailable)
+		_, _, err := Publish(ctx, producer, "orders", "key", "v", nil)
+		if !errors.Is(err, sarama.ErrLeaderNotAvailable) || !strings.HasPrefix(err.Error(), "kafka: failed to publish to orders: ") {
+			t.Fatalf("Publish() error = %v, want the wrapped send error", err)
+		}
+	})
+}
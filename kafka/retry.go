@@ -0,0 +1,153 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/11SF/go-common/backoff"
+	"github.com/Shopify/sarama"
+	"go.uber.org/zap"
+)
+
+// Headers added to messages sent to a dead-letter topic.
+const (
+	HeaderDLQOriginalTopic     = "x-original-topic"
+	HeaderDLQOriginalPartition = "x-original-partition"
+	HeaderDLQOriginalOffset    = "x-original-offset"
+	HeaderDLQError             = "x-error"
+	HeaderDLQAttempts          = "x-attempts"
+)
+
+// RetryPolicy retries a failed message in-process before giving up on it,
+// waiting backoff.Exponential delays between attempts. MaxAttempts counts
+// the first try; zero or one disables retries.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	// MaxBackoff caps the delay between attempts. Zero uses
+	// backoff.DefaultMax.
+	MaxBackoff time.Duration
+}
+
+func (rp RetryPolicy) delay(retry int) time.Duration {
+	return backoff.Exponential(rp.Backoff, rp.MaxBackoff, retry)
+}
+
+// WithRetryPolicy retries failed messages per policy.
+func WithRetryPolicy(policy RetryPolicy) ConsumerOption {
+	return func(cg *ConsumerGroup) {
+		cg.retry = policy
+	}
+}
+
+// DLQFailureMode decides what happens when a message cannot be written to
+// the dead-letter topic.
+type DLQFailureMode int
+
+const (
+	// DLQBlock keeps retrying the dead-letter write, holding the partition,
+	// until it succeeds or the consumer stops.
+	DLQBlock DLQFailureMode = iota
+	// DLQSkip logs the failure and moves past the message.
+	DLQSkip
+)
+
+// dlqRetryBackoff is the wait between dead-letter writes in DLQBlock mode.
+const dlqRetryBackoff = time.Second
+
+type deadLetterQueue struct {
+	topic    string
+	producer sarama.SyncProducer
+	mode     DLQFailureMode
+}
+
+// WithDLQ sends messages that still fail after retries to topic through
+// producer, then commits their offset so the partition keeps moving. The
+// original key, value and headers are kept and the HeaderDLQ* headers are
+// added.
+func WithDLQ(topic string, producer sarama.SyncProducer, mode DLQFailureMode) ConsumerOption {
+	return func(cg *ConsumerGroup) {
+		cg.dlq = &deadLetterQueue{topic: topic, producer: producer, mode: mode}
+	}
+}
+
+// handleWithRetry runs the handler until it succeeds, the retry policy is
+// exhausted or ctx is done, returning the number of attempts made. Decode
+// errors are not retried.
+func (h *groupHandler) handleWithRetry(ctx context.Context, msg *sarama.ConsumerMessage) (int, error) {
+	attempts := 1
+	err := h.handle(h.ctx, msg)
+	for err != nil && attempts < h.cg.retry.MaxAttempts {
+		var decodeErr *decodeError
+		if errors.As(err, &decodeErr) {
+			break
+		}
+		if !sleepCtx(ctx, h.cg.retry.delay(attempts)) {
+			return attempts, err
+		}
+		attempts++
+		err = h.handle(h.ctx, msg)
+	}
+	return attempts, err
+}
+
+// send writes msg to the dead-letter topic. It reports false when ctx ended
+// before the message was dealt with, in which case the offset must not be
+// marked.
+func (dlq *deadLetterQueue) send(ctx context.Context, log *zap.Logger, msg *sarama.ConsumerMessage, cause error, attempts int) bool {
+	dead := &sarama.ProducerMessage{
+		Topic:   dlq.topic,
+		Key:     sarama.ByteEncoder(msg.Key),
+		Value:   sarama.ByteEncoder(msg.Value),
+		Headers: make([]sarama.RecordHeader, 0, len(msg.Headers)+5),
+	}
+	for _, header := range msg.Headers {
+		if header != nil {
+			dead.Headers = append(dead.Headers, *header)
+		}
+	}
+	dead.Headers = append(dead.Headers,
+		sarama.RecordHeader{Key: []byte(HeaderDLQOriginalTopic), Value: []byte(msg.Topic)},
+		sarama.RecordHeader{Key: []byte(HeaderDLQOriginalPartition), Value: []byte(strconv.FormatInt(int64(msg.Partition), 10))},
+		sarama.RecordHeader{Key: []byte(HeaderDLQOriginalOffset), Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		sarama.RecordHeader{Key: []byte(HeaderDLQError), Value: []byte(cause.Error())},
+		sarama.RecordHeader{Key: []byte(HeaderDLQAttempts), Value: []byte(strconv.Itoa(attempts))},
+	)
+
+	for {
+		_, _, err := dlq.producer.SendMessage(dead)
+		if err == nil {
+			return true
+		}
+		log.Error("kafka dead-letter write failed",
+			zap.String("dlq_topic", dlq.topic),
+			zap.String("topic", msg.Topic),
+			zap.Int32("partition", msg.Partition),
+			zap.Int64("offset", msg.Offset),
+			zap.Error(err),
+		)
+		if dlq.mode == DLQSkip {
+			return true
+		}
+		if !sleepCtx(ctx, dlqRetryBackoff) {
+			return false
+		}
+	}
+}
+
+// sleepCtx waits for d and reports false if ctx ended first.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,243 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/11SF/go-common/backoff"
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"go.uber.org/zap"
+)
+
+// fakeSession records the offsets marked by ConsumeClaim.
+type fakeSession struct {
+	ctx    context.Context
+	mu     sync.Mutex
+	marked []int64
+}
+
+func (s *fakeSession) Claims() map[string][]int32               { return nil }
+func (s *fakeSession) MemberID() string                         { return "member" }
+func (s *fakeSession) GenerationID() int32                      { return 1 }
+func (s *fakeSession) MarkOffset(string, int32, int64, string)  {}
+func (s *fakeSession) Commit()                                  {}
+func (s *fakeSession) ResetOffset(string, int32, int64, string) {}
+func (s *fakeSession) Context() context.Context                 { return s.ctx }
+func (s *fakeSession) MarkMessage(msg *sarama.ConsumerMessage, _ string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.marked = append(s.marked, msg.Offset)
+}
+
+func (s *fakeSession) markedOffsets() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int64(nil), s.marked...)
+}
+
+// fakeClaim delivers msgs and then closes, ending ConsumeClaim.
+type fakeClaim struct {
+	msgs chan *sarama.ConsumerMessage
+}
+
+func newFakeClaim(msgs ...*sarama.ConsumerMessage) *fakeClaim {
+	c := &fakeClaim{msgs: make(chan *sarama.ConsumerMessage, len(msgs))}
+	for _, msg := range msgs {
+		c.msgs <- msg
+	}
+	close(c.msgs)
+	return c
+}
+
+func (c *fakeClaim) Topic() string                            { return "orders" }
+func (c *fakeClaim) Partition() int32                         { return 3 }
+func (c *fakeClaim) InitialOffset() int64                     { return 0 }
+func (c *fakeClaim) HighWaterMarkOffset() int64               { return 0 }
+func (c *fakeClaim) Messages() <-chan *sarama.ConsumerMessage { return c.msgs }
+
+func testMessage(offset int64) *sarama.ConsumerMessage {
+	return &sarama.ConsumerMessage{
+		Topic:     "orders",
+		Partition: 3,
+		Offset:    offset,
+		Key:       []byte("key"),
+		Value:     []byte("value"),
+		Headers:   []*sarama.RecordHeader{{Key: []byte("trace"), Value: []byte("abc")}},
+	}
+}
+
+// consume runs ConsumeClaim over msgs with handler and returns the session.
+func consume(t *testing.T, ctx context.Context, handler Handler, opts []ConsumerOption, msgs ...*sarama.ConsumerMessage) *fakeSession {
+	t.Helper()
+	cg := newConsumerGroup(nil, append([]ConsumerOption{WithConsumerLogger(zap.NewNop())}, opts...))
+	h := &groupHandler{cg: cg, ctx: context.Background(), handler: handler}
+	session := &fakeSession{ctx: ctx}
+	if err := h.ConsumeClaim(session, newFakeClaim(msgs...)); err != nil {
+		t.Fatalf("ConsumeClaim() error = %v", err)
+	}
+	return session
+}
+
+// failingHandler fails the first failures calls for each offset and counts
+// calls per offset.
+func failingHandler(failures int, calls map[int64]int, mu *sync.Mutex) Handler {
+	return func(_ context.Context, msg *sarama.ConsumerMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls[msg.Offset]++
+		if calls[msg.Offset] <= failures {
+			return errors.New("boom")
+		}
+		return nil
+	}
+}
+
+func TestConsumeClaimRetry(t *testing.T) {
+	policy := WithRetryPolicy(RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond})
+
+	tests := []struct {
+		name       string
+		failures   int
+		opts       []ConsumerOption
+		wantCalls  int
+		wantMarked []int64
+	}{
+		{"success first try", 0, []ConsumerOption{policy}, 1, []int64{10, 11}},
+		{"success on last attempt", 2, []ConsumerOption{policy}, 3, []int64{10, 11}},
+		{"exhausted without DLQ is skipped", 3, []ConsumerOption{policy}, 3, nil},
+		{"no retry policy", 1, nil, 1, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			calls := map[int64]int{}
+			session := consume(t, context.Background(), failingHandler(tt.failures, calls, &mu), tt.opts, testMessage(10), testMessage(11))
+
+			for _, offset := range []int64{10, 11} {
+				if calls[offset] != tt.wantCalls {
+					t.Errorf("calls for offset %d = %d, want %d", offset, calls[offset], tt.wantCalls)
+				}
+			}
+			if got := session.markedOffsets(); !slices.Equal(got, tt.wantMarked) {
+				t.Errorf("marked = %v, want %v", got, tt.wantMarked)
+			}
+		})
+	}
+}
+
+func TestConsumeClaimSkippedMessageCommittedPast(t *testing.T) {
+	handler := func(_ context.Context, msg *sarama.ConsumerMessage) error {
+		if msg.Offset == 11 {
+			return errors.New("boom")
+		}
+		return nil
+	}
+	session := consume(t, context.Background(), handler, nil, testMessage(10), testMessage(11), testMessage(12))
+	if got, want := session.markedOffsets(), []int64{10, 12}; !slices.Equal(got, want) {
+		t.Errorf("marked = %v, want %v", got, want)
+	}
+}
+
+func TestConsumeClaimDLQ(t *testing.T) {
+	producer := mocks.NewSyncProducer(t, nil)
+	var dead *sarama.ProducerMessage
+	producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		dead = msg
+		return nil
+	})
+
+	var mu sync.Mutex
+	calls := map[int64]int{}
+	session := consume(t, context.Background(), failingHandler(5, calls, &mu), []ConsumerOption{
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond}),
+		WithDLQ("orders.dlq", producer, DLQBlock),
+	}, testMessage(10))
+	if err := producer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if calls[10] != 2 {
+		t.Errorf("calls = %d, want 2", calls[10])
+	}
+	if got, want := session.markedOffsets(), []int64{10}; !slices.Equal(got, want) {
+		t.Errorf("marked = %v, want %v", got, want)
+	}
+	if dead == nil {
+		t.Fatal("no dead-letter message sent")
+	}
+	if dead.Topic != "orders.dlq" {
+		t.Errorf("topic = %q, want orders.dlq", dead.Topic)
+	}
+	key, _ := dead.Key.Encode()
+	value, _ := dead.Value.Encode()
+	if string(key) != "key" || string(value) != "value" {
+		t.Errorf("key, value = %q, %q, want key, value", key, value)
+	}
+
+	headers := make(map[string]string, len(dead.Headers))
+	for _, header := range dead.Headers {
+		headers[string(header.Key)] = string(header.Value)
+	}
+	want := map[string]string{
+		"trace":                    "abc",
+		HeaderDLQOriginalTopic:     "orders",
+		HeaderDLQOriginalPartition: "3",
+		HeaderDLQOriginalOffset:    "10",
+		HeaderDLQError:             "boom",
+		HeaderDLQAttempts:          "2",
+	}
+	for name, value := range want {
+		if headers[name] != value {
+			t.Errorf("header %s = %q, want %q", name, headers[name], value)
+		}
+	}
+}
+
+func TestConsumeClaimDLQFailure(t *testing.T) {
+	handler := func(context.Context, *sarama.ConsumerMessage) error {
+		return errors.New("boom")
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		producer := mocks.NewSyncProducer(t, nil)
+		producer.ExpectSendMessageAndFail(sarama.ErrOutOfBrokers)
+		producer.ExpectSendMessageAndSucceed()
+
+		session := consume(t, context.Background(), handler, []ConsumerOption{WithDLQ("orders.dlq", producer, DLQSkip)}, testMessage(10), testMessage(11))
+		producer.Close()
+		if got, want := session.markedOffsets(), []int64{10, 11}; !slices.Equal(got, want) {
+			t.Errorf("marked = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("block until stopped", func(t *testing.T) {
+		producer := mocks.NewSyncProducer(t, nil)
+		producer.ExpectSendMessageAndFail(sarama.ErrOutOfBrokers)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		session := consume(t, ctx, handler, []ConsumerOption{WithDLQ("orders.dlq", producer, DLQBlock)}, testMessage(10), testMessage(11))
+		producer.Close()
+		if got := session.markedOffsets(); len(got) != 0 {
+			t.Errorf("marked = %v, want none", got)
+		}
+	})
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	rp := RetryPolicy{Backoff: time.Second, MaxBackoff: 10 * time.Second}
+	for retry, want := range map[int]time.Duration{1: time.Second, 3: 4 * time.Second, 5: 10 * time.Second, 64: 10 * time.Second} {
+		if got := rp.delay(retry); got != want {
+			t.Errorf("delay(%d) = %s, want %s", retry, got, want)
+		}
+	}
+	if got := (RetryPolicy{Backoff: time.Second}).delay(40); got != backoff.DefaultMax {
+		t.Errorf("delay(40) without MaxBackoff = %s, want %s", got, backoff.DefaultMax)
+	}
+}
@@ -0,0 +1,154 @@
+package kafka
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/Shopify/sarama"
+	"github.com/xdg-go/scram"
+)
+
+// SASL mechanisms accepted in SASLConfig.Mechanism.
+const (
+	SASLMechanismPlain       = "PLAIN"
+	SASLMechanismSCRAMSHA256 = "SCRAM-SHA-256"
+	SASLMechanismSCRAMSHA512 = "SCRAM-SHA-512"
+)
+
+// TLSConfig enables TLS to the brokers. PEM fields hold the certificate
+// contents, not file paths.
+type TLSConfig struct {
+	Enabled bool
+	// CACertPEM is added to the system roots when set.
+	CACertPEM string
+	// ClientCertPEM and ClientKeyPEM enable mutual TLS and must be set
+	// together.
+	ClientCertPEM      string
+	ClientKeyPEM       string
+	InsecureSkipVerify bool
+}
+
+// SASLConfig enables SASL authentication. An empty Mechanism disables it.
+type SASLConfig struct {
+	Mechanism string
+	Username  string
+	Password  string
+}
+
+// BuildSaramaConfig returns a copy of cf.Config with TLS and SASL applied,
+// validated with sarama's Validate. Incomplete TLS or SASL settings are
+// reported as errors here rather than when the first broker connection fails.
+func (cf *Config) BuildSaramaConfig() (*sarama.Config, error) {
+	config := cf.Config
+
+	if err := applyTLS(&config, cf.TLS); err != nil {
+		return nil, err
+	}
+	if err := applySASL(&config, cf.SASL); err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		// sarama's ConfigurationError already reads "kafka: invalid configuration (...)".
+		return nil, err
+	}
+	return &config, nil
+}
+
+func applyTLS(config *sarama.Config, cf TLSConfig) error {
+	if !cf.Enabled {
+		return nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cf.InsecureSkipVerify,
+	}
+	if cf.CACertPEM != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(cf.CACertPEM)) {
+			return errors.New("kafka: no certificates found in TLS CACertPEM")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	switch {
+	case cf.ClientCertPEM != "" && cf.ClientKeyPEM != "":
+		cert, err := tls.X509KeyPair([]byte(cf.ClientCertPEM), []byte(cf.ClientKeyPEM))
+		if err != nil {
+			return fmt.Errorf("kafka: invalid TLS client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	case cf.ClientCertPEM != "":
+		return errors.New("kafka: TLS ClientCertPEM is set without ClientKeyPEM")
+	case cf.ClientKeyPEM != "":
+		return errors.New("kafka: TLS ClientKeyPEM is set without ClientCertPEM")
+	}
+
+	config.Net.TLS.Enable = true
+	config.Net.TLS.Config = tlsConfig
+	return nil
+}
+
+func applySASL(config *sarama.Config, cf SASLConfig) error {
+	if cf.Mechanism == "" {
+		return nil
+	}
+	if cf.Username == "" || cf.Password == "" {
+		return fmt.Errorf("kafka: SASL %s requires Username and Password", cf.Mechanism)
+	}
+
+	switch cf.Mechanism {
+	case SASLMechanismPlain:
+		config.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+	case SASLMechanismSCRAMSHA256:
+		config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA256
+		config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+			return &XDGSCRAMClient{HashGeneratorFcn: sha256.New}
+		}
+	case SASLMechanismSCRAMSHA512:
+		config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA512
+		config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+			return &XDGSCRAMClient{HashGeneratorFcn: sha512.New}
+		}
+	default:
+		return fmt.Errorf("kafka: unsupported SASL mechanism %q", cf.Mechanism)
+	}
+
+	config.Net.SASL.Enable = true
+	config.Net.SASL.Handshake = true
+	config.Net.SASL.User = cf.Username
+	config.Net.SASL.Password = cf.Password
+	return nil
+}
+
+// XDGSCRAMClient implements sarama.SCRAMClient with github.com/xdg-go/scram.
+type XDGSCRAMClient struct {
+	*scram.Client
+	*scram.ClientConversation
+	scram.HashGeneratorFcn
+}
+
+func (x *XDGSCRAMClient) Begin(userName, password, authzID string) error {
+	client, err := x.HashGeneratorFcn.NewClient(userName, password, authzID)
+	if err != nil {
+		return err
+	}
+	x.Client = client
+	x.ClientConversation = client.NewConversation()
+	return nil
+}
+
+func (x *XDGSCRAMClient) Step(challenge string) (string, error) {
+	return x.ClientConversation.Step(challenge)
+}
+
+func (x *XDGSCRAMClient) Done() bool {
+	return x.ClientConversation.Done()
+}
@@ -0,0 +1,207 @@
+package kafka
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+// selfSigned returns a PEM certificate and key for a throwaway CA.
+func selfSigned(t *testing.T) (certPEM, keyPEM string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "kafka-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	keyPEM = string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+	return certPEM, keyPEM
+}
+
+func newSecurityConfig(tlsConfig TLSConfig, sasl SASLConfig) *Config {
+	return &Config{Config: *sarama.NewConfig(), TLS: tlsConfig, SASL: sasl}
+}
+
+func TestBuildSaramaConfigSASL(t *testing.T) {
+	tests := []struct {
+		mechanism string
+		want      sarama.SASLMechanism
+		hashSize  int
+	}{
+		{SASLMechanismPlain, sarama.SASLTypePlaintext, 0},
+		{SASLMechanismSCRAMSHA256, sarama.SASLTypeSCRAMSHA256, sha256.Size},
+		{SASLMechanismSCRAMSHA512, sarama.SASLTypeSCRAMSHA512, sha512.Size},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mechanism, func(t *testing.T) {
+			cf := newSecurityConfig(TLSConfig{}, SASLConfig{Mechanism: tt.mechanism, Username: "svc", Password: "secret"})
+			config, err := cf.BuildSaramaConfig()
+			if err != nil {
+				t.Fatalf("BuildSaramaConfig() error = %v", err)
+			}
+
+			sasl := config.Net.SASL
+			if !sasl.Enable || !sasl.Handshake || sasl.Mechanism != tt.want || sasl.User != "svc" || sasl.Password != "secret" {
+				t.Errorf("Net.SASL = {Enable:%v Handshake:%v Mechanism:%s User:%q Password:%q}, want enabled %s for svc",
+					sasl.Enable, sasl.Handshake, sasl.Mechanism, sasl.User, sasl.Password, tt.want)
+			}
+			if cf.Config.Net.SASL.Enable {
+				t.Error("BuildSaramaConfig() modified cf.Config")
+			}
+
+			if tt.hashSize == 0 {
+				if sasl.SCRAMClientGeneratorFunc != nil {
+					t.Error("SCRAMClientGeneratorFunc set for PLAIN")
+				}
+				return
+			}
+			client, ok := sasl.SCRAMClientGeneratorFunc().(*XDGSCRAMClient)
+			if !ok {
+				t.Fatal("SCRAMClientGeneratorFunc() did not return an *XDGSCRAMClient")
+			}
+			if size := client.HashGeneratorFcn().Size(); size != tt.hashSize {
+				t.Errorf("SCRAM hash size = %d, want %d", size, tt.hashSize)
+			}
+			if err := client.Begin("svc", "secret", ""); err != nil {
+				t.Fatalf("Begin() error = %v", err)
+			}
+			first, err := client.Step("")
+			if err != nil || !strings.HasPrefix(first, "n,,n=svc,r=") {
+				t.Errorf("Step() = %q, %v, want a client-first message for svc", first, err)
+			}
+			if client.Done() {
+				t.Error("Done() = true after the first step")
+			}
+		})
+	}
+}
+
+func TestBuildSaramaConfigTLS(t *testing.T) {
+	certPEM, keyPEM := selfSigned(t)
+
+	cf := newSecurityConfig(TLSConfig{
+		Enabled:       true,
+		CACertPEM:     certPEM,
+		ClientCertPEM: certPEM,
+		ClientKeyPEM:  keyPEM,
+	}, SASLConfig{Mechanism: SASLMechanismSCRAMSHA512, Username: "svc", Password: "secret"})
+	config, err := cf.BuildSaramaConfig()
+	if err != nil {
+		t.Fatalf("BuildSaramaConfig() error = %v", err)
+	}
+
+	if !config.Net.TLS.Enable || config.Net.TLS.Config == nil {
+		t.Fatal("Net.TLS not enabled")
+	}
+	tlsConfig := config.Net.TLS.Config
+	if tlsConfig.MinVersion != tls.VersionTLS12 || tlsConfig.InsecureSkipVerify {
+		t.Errorf("tls.Config MinVersion = %x, InsecureSkipVerify = %v, want TLS 1.2 and verification", tlsConfig.MinVersion, tlsConfig.InsecureSkipVerify)
+	}
+	if tlsConfig.RootCAs == nil {
+		t.Error("RootCAs not set from CACertPEM")
+	}
+	if len(tlsConfig.Certificates) != 1 {
+		t.Errorf("got %d client certificates, want 1", len(tlsConfig.Certificates))
+	}
+	if !config.Net.SASL.Enable {
+		t.Error("SASL not applied alongside TLS")
+	}
+
+	disabled, err := newSecurityConfig(TLSConfig{CACertPEM: certPEM}, SASLConfig{}).BuildSaramaConfig()
+	if err != nil {
+		t.Fatalf("BuildSaramaConfig() error = %v", err)
+	}
+	if disabled.Net.TLS.Enable || disabled.Net.SASL.Enable {
+		t.Error("TLS or SASL enabled without being configured")
+	}
+}
+
+func TestBuildSaramaConfigErrors(t *testing.T) {
+	certPEM, keyPEM := selfSigned(t)
+	_, otherKeyPEM := selfSigned(t)
+
+	tests := []struct {
+		name    string
+		tls     TLSConfig
+		sasl    SASLConfig
+		wantErr string
+	}{
+		{
+			name:    "cert without key",
+			tls:     TLSConfig{Enabled: true, ClientCertPEM: certPEM},
+			wantErr: "kafka: TLS ClientCertPEM is set without ClientKeyPEM",
+		},
+		{
+			name:    "key without cert",
+			tls:     TLSConfig{Enabled: true, ClientKeyPEM: keyPEM},
+			wantErr: "kafka: TLS ClientKeyPEM is set without ClientCertPEM",
+		},
+		{
+			name:    "mismatched key",
+			tls:     TLSConfig{Enabled: true, ClientCertPEM: certPEM, ClientKeyPEM: otherKeyPEM},
+			wantErr: "kafka: invalid TLS client certificate: ",
+		},
+		{
+			name:    "CA without certificates",
+			tls:     TLSConfig{Enabled: true, CACertPEM: "not a certificate"},
+			wantErr: "kafka: no certificates found in TLS CACertPEM",
+		},
+		{
+			name:    "SCRAM without password",
+			sasl:    SASLConfig{Mechanism: SASLMechanismSCRAMSHA512, Username: "svc"},
+			wantErr: "kafka: SASL SCRAM-SHA-512 requires Username and Password",
+		},
+		{
+			name:    "PLAIN without username",
+			sasl:    SASLConfig{Mechanism: SASLMechanismPlain, Password: "secret"},
+			wantErr: "kafka: SASL PLAIN requires Username and Password",
+		},
+		{
+			name:    "unknown mechanism",
+			sasl:    SASLConfig{Mechanism: "GSSAPI", Username: "svc", Password: "secret"},
+			wantErr: `kafka: unsupported SASL mechanism "GSSAPI"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := newSecurityConfig(tt.tls, tt.sasl).BuildSaramaConfig()
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Fatalf("BuildSaramaConfig() error = %v, want %q", err, tt.wantErr)
+			}
+			if config != nil {
+				t.Error("BuildSaramaConfig() returned a config with its error")
+			}
+		})
+	}
+}
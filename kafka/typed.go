@@ -0,0 +1,82 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Shopify/sarama"
+	"go.uber.org/zap"
+)
+
+// DecodeErrorMode decides what RunTyped does with a message whose value
+// cannot be decoded.
+type DecodeErrorMode int
+
+const (
+	// DecodeErrorSkip logs the message and commits past it.
+	DecodeErrorSkip DecodeErrorMode = iota
+	// DecodeErrorDLQ sends the message to the dead-letter topic without
+	// retrying. The consumer group must be created with WithDLQ.
+	DecodeErrorDLQ
+	// DecodeErrorFail stops the consumer and returns the decode error from
+	// RunTyped, leaving the message uncommitted.
+	DecodeErrorFail
+)
+
+// TypedOptions configures RunTyped.
+type TypedOptions struct {
+	OnDecodeError DecodeErrorMode
+}
+
+// decodeError marks a failure that retrying cannot fix.
+type decodeError struct {
+	msg *sarama.ConsumerMessage
+	err error
+}
+
+func (e *decodeError) Error() string {
+	return fmt.Sprintf("kafka: failed to decode message %s/%d@%d: %v", e.msg.Topic, e.msg.Partition, e.msg.Offset, e.err)
+}
+
+func (e *decodeError) Unwrap() error {
+	return e.err
+}
+
+// RunTyped runs cg like Run, decoding each message value as JSON into T
+// before calling handler with the message key and decoded value. Messages
+// that fail to decode are handled per opts.OnDecodeError; handler errors go
+// through the usual retry and dead-letter handling.
+func RunTyped[T any](ctx context.Context, cg *ConsumerGroup, topics []string, handler func(ctx context.Context, key string, value T, raw *sarama.ConsumerMessage) error, opts TypedOptions) error {
+	if opts.OnDecodeError == DecodeErrorDLQ && cg.dlq == nil {
+		return errors.New("kafka: DecodeErrorDLQ requires a consumer group created WithDLQ")
+	}
+
+	runCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	err := cg.Run(runCtx, topics, func(ctx context.Context, msg *sarama.ConsumerMessage) error {
+		var value T
+		if err := json.Unmarshal(msg.Value, &value); err != nil {
+			decodeErr := &decodeError{msg: msg, err: err}
+			switch opts.OnDecodeError {
+			case DecodeErrorDLQ:
+				return decodeErr
+			case DecodeErrorFail:
+				cancel(decodeErr)
+				return decodeErr
+			default:
+				cg.log.Warn("skipping undecodable kafka message", zap.Error(decodeErr))
+				return nil
+			}
+		}
+		return handler(ctx, string(msg.Key), value, msg)
+	})
+
+	var decodeErr *decodeError
+	if cause := context.Cause(runCtx); errors.As(cause, &decodeErr) {
+		return decodeErr
+	}
+	return err
+}
@@ -0,0 +1,201 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type typedOrder struct {
+	ID    int    `json:"id"`
+	Owner string `json:"owner"`
+}
+
+const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func jsonMessage(offset int64, value string) *sarama.ConsumerMessage {
+	msg := testMessage(offset)
+	msg.Value = []byte(value)
+	msg.Headers = []*sarama.RecordHeader{{Key: []byte("traceparent"), Value: []byte(traceparent)}}
+	return msg
+}
+
+// typedRun is what a RunTyped handler saw.
+type typedRun struct {
+	keys    []string
+	values  []typedOrder
+	headers []string
+	err     error
+	group   *fakeGroup
+}
+
+// runTyped feeds batch to RunTyped as one session and returns once the
+// session has ended or RunTyped has returned.
+func runTyped(t *testing.T, mode DecodeErrorMode, opts []ConsumerOption, batch ...*sarama.ConsumerMessage) *typedRun {
+	t.Helper()
+	group := newFakeGroup()
+	cg := newConsumerGroup(group, opts)
+	run := &typedRun{group: group}
+
+	handler := func(_ context.Context, key string, value typedOrder, raw *sarama.ConsumerMessage) error {
+		run.keys = append(run.keys, key)
+		run.values = append(run.values, value)
+		for _, header := range raw.Headers {
+			if string(header.Key) == "traceparent" {
+				run.headers = append(run.headers, string(header.Value))
+			}
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	group.sessions <- batch
+	done := make(chan error, 1)
+	go func() {
+		done <- RunTyped(ctx, cg, []string{"orders"}, handler, TypedOptions{OnDecodeError: mode})
+	}()
+
+	// A second Consume call means the session has been fully handled.
+	deadline := time.Now().Add(5 * time.Second)
+	for group.calls.Load() < 2 {
+		select {
+		case run.err = <-done:
+			return run
+		default:
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("session was not handled")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	run.err = waitRun(t, done)
+	return run
+}
+
+func TestRunTypedDecodes(t *testing.T) {
+	run := runTyped(t, DecodeErrorSkip, []ConsumerOption{WithConsumerLogger(zap.NewNop())},
+		jsonMessage(1, `{"id":1,"owner":"ann"}`),
+		jsonMessage(2, `{"id":2,"owner":"bob","extra":true}`),
+	)
+	if run.err != nil {
+		t.Fatalf("RunTyped() error = %v", run.err)
+	}
+
+	want := []typedOrder{{1, "ann"}, {2, "bob"}}
+	if !slices.Equal(run.values, want) {
+		t.Errorf("decoded values = %v, want %v", run.values, want)
+	}
+	if !slices.Equal(run.keys, []string{"key", "key"}) {
+		t.Errorf("keys = %v, want the message keys", run.keys)
+	}
+	if !slices.Equal(run.headers, []string{traceparent, traceparent}) {
+		t.Errorf("traceparent headers = %v, want them on every raw message", run.headers)
+	}
+	if marked := run.group.markedOffsets(); len(marked) != 1 || !slices.Equal(marked[0], []int64{1, 2}) {
+		t.Errorf("marked offsets = %v, want [[1 2]]", marked)
+	}
+}
+
+func TestRunTypedDecodeErrorSkip(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	run := runTyped(t, DecodeErrorSkip, []ConsumerOption{WithConsumerLogger(zap.New(core))},
+		jsonMessage(1, `{"id":`),
+		jsonMessage(2, `{"id":2,"owner":"bob"}`),
+	)
+	if run.err != nil {
+		t.Fatalf("RunTyped() error = %v", run.err)
+	}
+
+	if !slices.Equal(run.values, []typedOrder{{2, "bob"}}) {
+		t.Errorf("decoded values = %v, want only the valid message", run.values)
+	}
+	if marked := run.group.markedOffsets(); len(marked) != 1 || !slices.Equal(marked[0], []int64{1, 2}) {
+		t.Errorf("marked offsets = %v, want the malformed message committed past", marked)
+	}
+	if n := logs.FilterMessage("skipping undecodable kafka message").Len(); n != 1 {
+		t.Errorf("logged %d skipped messages, want 1", n)
+	}
+}
+
+func TestRunTypedDecodeErrorDLQ(t *testing.T) {
+	producer := newMockSyncProducer(t)
+	var dead *sarama.ProducerMessage
+	producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		dead = msg
+		return nil
+	})
+
+	run := runTyped(t, DecodeErrorDLQ, []ConsumerOption{
+		WithConsumerLogger(zap.NewNop()),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}),
+		WithDLQ("orders.dlq", producer, DLQBlock),
+	},
+		jsonMessage(1, `not json`),
+		jsonMessage(2, `{"id":2,"owner":"bob"}`),
+	)
+	if run.err != nil {
+		t.Fatalf("RunTyped() error = %v", run.err)
+	}
+
+	if dead == nil {
+		t.Fatal("malformed message not sent to the DLQ")
+	}
+	if got := encoded(t, dead.Value); got != "not json" {
+		t.Errorf("DLQ value = %q, want the raw payload", got)
+	}
+	headers := headerMap(dead.Headers)
+	if headers[HeaderDLQAttempts] != "1" {
+		t.Errorf("%s = %q, want 1: decode errors are not retried", HeaderDLQAttempts, headers[HeaderDLQAttempts])
+	}
+	if headers["traceparent"] != traceparent {
+		t.Errorf("traceparent = %q, want it carried to the DLQ", headers["traceparent"])
+	}
+	if !slices.Equal(run.values, []typedOrder{{2, "bob"}}) {
+		t.Errorf("decoded values = %v, want only the valid message", run.values)
+	}
+	if marked := run.group.markedOffsets(); len(marked) != 1 || !slices.Equal(marked[0], []int64{1, 2}) {
+		t.Errorf("marked offsets = %v, want [[1 2]]", marked)
+	}
+}
+
+func TestRunTypedDecodeErrorFail(t *testing.T) {
+	run := runTyped(t, DecodeErrorFail, []ConsumerOption{WithConsumerLogger(zap.NewNop())},
+		jsonMessage(1, `{"id":1,"owner":"ann"}`),
+		jsonMessage(2, `{"id":"two"}`),
+		jsonMessage(3, `{"id":3,"owner":"cy"}`),
+	)
+
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(run.err, &typeErr) {
+		t.Fatalf("RunTyped() error = %v, want the decode error", run.err)
+	}
+	if want := "kafka: failed to decode message orders/3@2: "; !strings.HasPrefix(run.err.Error(), want) {
+		t.Errorf("RunTyped() error = %q, want prefix %q", run.err, want)
+	}
+	if !slices.Equal(run.values, []typedOrder{{1, "ann"}}) {
+		t.Errorf("decoded values = %v, want processing to stop at the bad message", run.values)
+	}
+	if marked := run.group.markedOffsets(); len(marked) != 1 || !slices.Equal(marked[0], []int64{1}) {
+		t.Errorf("marked offsets = %v, want the bad message left uncommitted", marked)
+	}
+}
+
+func TestRunTypedDLQModeRequiresDLQ(t *testing.T) {
+	cg := newConsumerGroup(newFakeGroup(), nil)
+	handler := func(context.Context, string, typedOrder, *sarama.ConsumerMessage) error { return nil }
+	err := RunTyped(context.Background(), cg, []string{"orders"}, handler, TypedOptions{OnDecodeError: DecodeErrorDLQ})
+	if err == nil || err.Error() != "kafka: DecodeErrorDLQ requires a consumer group created WithDLQ" {
+		t.Fatalf("RunTyped() error = %v", err)
+	}
+}
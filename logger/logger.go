@@ -3,6 +3,7 @@ package logger
 import (
 	"os"
 
+	"github.com/11SF/go-common/version"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -26,6 +27,8 @@ func CreateLogger(cf Config) *zap.Logger {
 		zapLevel = zap.DebugLevel
 	}
 
+	build := version.Info()
+
 	config := zap.Config{
 		Level:             zap.NewAtomicLevelAt(zapLevel),
 		Development:       false,
@@ -44,6 +47,8 @@ func CreateLogger(cf Config) *zap.Logger {
 		InitialFields: map[string]interface{}{
 			"pid":      os.Getpid(),
 			"hostname": hostname,
+			"version":  build.Version,
+			"commit":   build.Commit,
 		},
 	}
 
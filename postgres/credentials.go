@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// PasswordProvider returns the password for a new connection and the time it
+// stops being valid. The password is cached until expiresAt; a zero expiresAt
+// disables caching so the provider is called for every connection.
+type PasswordProvider func(ctx context.Context) (password string, expiresAt time.Time, err error)
+
+// passwordCache serialises provider calls so a burst of new connections
+// fetches one credential.
+type passwordCache struct {
+	provider PasswordProvider
+
+	mu        sync.Mutex
+	password  string
+	expiresAt time.Time
+}
+
+func (pc *passwordCache) get(ctx context.Context) (string, error) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	if !pc.expiresAt.IsZero() && time.Now().Before(pc.expiresAt) {
+		return pc.password, nil
+	}
+
+	password, expiresAt, err := pc.provider(ctx)
+	if err != nil {
+		return "", err
+	}
+	pc.password = password
+	pc.expiresAt = expiresAt
+	return password, nil
+}
+
+// connectWithProvider opens the pool through pgx's stdlib connector so the
+// password can be swapped in before every dial.
+func connectWithProvider(dsn string, cf *Config) (gorm.Dialector, error) {
+	connConfig, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: failed to parse dsn: %w", err)
+	}
+	if cf.PreferSimpleProtocol {
+		connConfig.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+	}
+
+	dial := &providerDialector{
+		Dialector:  postgres.New(postgres.Config{}).(*postgres.Dialector),
+		connConfig: *connConfig,
+		cache:      &passwordCache{provider: cf.PasswordProvider},
+	}
+	return dial, nil
+}
+
+// providerDialector opens a new pool on every Initialize. A failed connect
+// closes its pool, so reusing one pool would make retries with the same
+// dialector fail with "sql: database is closed".
+type providerDialector struct {
+	*postgres.Dialector
+	connConfig pgx.ConnConfig
+	cache      *passwordCache
+}
+
+func (d *providerDialector) Initialize(db *gorm.DB) error {
+	sqlDB := stdlib.OpenDB(d.connConfig, stdlib.OptionBeforeConnect(d.beforeConnect))
+	d.Dialector = postgres.New(postgres.Config{Conn: sqlDB}).(*postgres.Dialector)
+	return d.Dialector.Initialize(db)
+}
+
+func (d *providerDialector) beforeConnect(ctx context.Context, cc *pgx.ConnConfig) error {
+	password, err := d.cache.get(ctx)
+	if err != nil {
+		return fmt.Errorf("postgres: failed to fetch password for %s@%s: %w", cc.User, cc.Host, err)
+	}
+	cc.Password = password
+	return nil
+}
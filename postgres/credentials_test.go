@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// countingProvider returns "secret" valid for ttl and counts its calls. A
+// zero ttl returns a zero expiry.
+func countingProvider(calls *atomic.Int32, ttl time.Duration) PasswordProvider {
+	return func(context.Context) (string, time.Time, error) {
+		calls.Add(1)
+		if ttl == 0 {
+			return "secret", time.Time{}, nil
+		}
+		return "secret", time.Now().Add(ttl), nil
+	}
+}
+
+// pingTimes opens a pool through ConnectPostgres and pings it n times.
+// Nothing listens on port 1, so every ping dials a new connection and runs
+// the provider hook before failing.
+func pingTimes(t *testing.T, provider PasswordProvider, n int, between time.Duration) error {
+	t.Helper()
+	dial, err := ConnectPostgres(&Config{
+		Host:             "127.0.0.1",
+		Port:             1,
+		Username:         "app",
+		DBName:           "orders",
+		SSLMode:          "disable",
+		PasswordProvider: provider,
+	})
+	if err != nil {
+		t.Fatalf("ConnectPostgres() error = %v", err)
+	}
+	db, err := gorm.Open(dial, &gorm.Config{Logger: gormlogger.Discard, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+
+	var last error
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			time.Sleep(between)
+		}
+		last = sqlDB.PingContext(context.Background())
+		if last == nil {
+			t.Fatal("PingContext() succeeded with nothing listening")
+		}
+	}
+	return last
+}
+
+func TestPasswordProviderPerConnection(t *testing.T) {
+	tests := []struct {
+		name      string
+		ttl       time.Duration
+		between   time.Duration
+		wantCalls int32
+	}{
+		{"cached until expiry", time.Hour, 0, 1},
+		{"zero expiry is not cached", 0, 0, 3},
+		{"expired password is fetched again", 20 * time.Millisecond, 50 * time.Millisecond, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			pingTimes(t, countingProvider(&calls, tt.ttl), 3, tt.between)
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("provider called %d times for 3 connections, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestPasswordProviderError(t *testing.T) {
+	errVault := errors.New("vault sealed")
+	err := pingTimes(t, func(context.Context) (string, time.Time, error) {
+		return "", time.Time{}, errVault
+	}, 1, 0)
+
+	if !errors.Is(err, errVault) {
+		t.Errorf("PingContext() error = %v, want it to wrap the provider error", err)
+	}
+	if !strings.Contains(err.Error(), "postgres: failed to fetch password for app@127.0.0.1") {
+		t.Errorf("PingContext() error = %v, want the connection named", err)
+	}
+}
+
+// Run with -race: a burst of connections shares one provider call.
+func TestPasswordCacheConcurrent(t *testing.T) {
+	var calls atomic.Int32
+	cache := &passwordCache{provider: countingProvider(&calls, time.Hour)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if password, err := cache.get(context.Background()); err != nil || password != "secret" {
+				t.Errorf("get() = %q, %v", password, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("provider called %d times, want 1", got)
+	}
+}
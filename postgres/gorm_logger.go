@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/11SF/go-common/logger"
+	"go.uber.org/zap"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+)
+
+type GormLoggerOptions struct {
+	// Logger receives the records. Nil uses logger.CreateLogger with the
+	// default config.
+	Logger *zap.Logger
+	// SlowThreshold escalates queries slower than it to WARN. Zero disables
+	// slow-query warnings.
+	SlowThreshold time.Duration
+	// IgnoreRecordNotFoundError suppresses gorm.ErrRecordNotFound errors.
+	IgnoreRecordNotFoundError bool
+	// LogLevel defaults to gormlogger.Warn.
+	LogLevel gormlogger.LogLevel
+}
+
+type gormLogger struct {
+	log  *zap.Logger
+	opts GormLoggerOptions
+}
+
+// NewGormLogger returns a gorm logger that writes structured records
+// through the logger package instead of gorm's plain stdout lines. Wire it
+// in with:
+//
+//	database.Config{
+//		Dial:       dial,
+//		GormConfig: gorm.Config{Logger: postgres.NewGormLogger(opts)},
+//	}
+func NewGormLogger(opts GormLoggerOptions) gormlogger.Interface {
+	log := opts.Logger
+	if log == nil {
+		log = logger.CreateLogger(logger.Config{})
+	}
+	if opts.LogLevel == 0 {
+		opts.LogLevel = gormlogger.Warn
+	}
+
+	return &gormLogger{
+		log:  log.WithOptions(zap.AddCallerSkip(1)),
+		opts: opts,
+	}
+}
+
+func (l *gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	next := *l
+	next.opts.LogLevel = level
+	return &next
+}
+
+func (l *gormLogger) Info(_ context.Context, msg string, args ...interface{}) {
+	if l.opts.LogLevel >= gormlogger.Info {
+		l.log.Info(fmt.Sprintf(msg, args...), zap.String("caller_file", utils.FileWithLineNum()))
+	}
+}
+
+func (l *gormLogger) Warn(_ context.Context, msg string, args ...interface{}) {
+	if l.opts.LogLevel >= gormlogger.Warn {
+		l.log.Warn(fmt.Sprintf(msg, args...), zap.String("caller_file", utils.FileWithLineNum()))
+	}
+}
+
+func (l *gormLogger) Error(_ context.Context, msg string, args ...interface{}) {
+	if l.opts.LogLevel >= gormlogger.Error {
+		l.log.Error(fmt.Sprintf(msg, args...), zap.String("caller_file", utils.FileWithLineNum()))
+	}
+}
+
+func (l *gormLogger) Trace(_ context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.opts.LogLevel <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	fields := func() []zap.Field {
+		sql, rows := fc()
+		return []zap.Field{
+			zap.String("sql", sql),
+			zap.Int64("rows", rows),
+			zap.Duration("elapsed", elapsed),
+			zap.String("caller_file", utils.FileWithLineNum()),
+		}
+	}
+
+	switch {
+	case err != nil && l.opts.LogLevel >= gormlogger.Error &&
+		!(l.opts.IgnoreRecordNotFoundError && errors.Is(err, gormlogger.ErrRecordNotFound)):
+		l.log.Error("gorm query failed", append(fields(), zap.Error(err))...)
+	case l.opts.SlowThreshold > 0 && elapsed > l.opts.SlowThreshold && l.opts.LogLevel >= gormlogger.Warn:
+		l.log.Warn("gorm slow query", append(fields(), zap.Duration("threshold", l.opts.SlowThreshold))...)
+	case l.opts.LogLevel >= gormlogger.Info:
+		l.log.Info("gorm query", fields()...)
+	}
+}
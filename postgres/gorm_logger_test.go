@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+type loggedRow struct {
+	ID   int
+	Name string
+}
+
+// openLoggedSQLite opens a SQLite database with one empty logged_rows table
+// and the gorm logger built from opts writing to the returned logs.
+func openLoggedSQLite(t *testing.T, opts GormLoggerOptions) (*gorm.DB, *observer.ObservedLogs) {
+	t.Helper()
+	core, logs := observer.New(zapcore.DebugLevel)
+	opts.Logger = zap.New(core)
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "app.db")), &gorm.Config{Logger: NewGormLogger(opts)})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { closeDB(t, db) })
+	if err := db.Exec("CREATE TABLE logged_rows (id INTEGER PRIMARY KEY, name TEXT)").Error; err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	// Drop the records of the setup statements.
+	logs.TakeAll()
+	return db, logs
+}
+
+func TestGormLogger(t *testing.T) {
+	type entry struct {
+		level   zapcore.Level
+		message string
+	}
+
+	tests := []struct {
+		name  string
+		opts  GormLoggerOptions
+		query func(db *gorm.DB) error
+		want  []entry
+	}{
+		{
+			name:  "fast query at default level",
+			opts:  GormLoggerOptions{SlowThreshold: time.Hour},
+			query: func(db *gorm.DB) error { return db.Exec("SELECT 1").Error },
+		},
+		{
+			name:  "slow query",
+			opts:  GormLoggerOptions{SlowThreshold: time.Nanosecond},
+			query: func(db *gorm.DB) error { return db.Exec("SELECT 1").Error },
+			want:  []entry{{zapcore.WarnLevel, "gorm slow query"}},
+		},
+		{
+			name:  "record not found",
+			query: func(db *gorm.DB) error { return db.First(&loggedRow{}).Error },
+			want:  []entry{{zapcore.ErrorLevel, "gorm query failed"}},
+		},
+		{
+			name:  "record not found ignored",
+			opts:  GormLoggerOptions{IgnoreRecordNotFoundError: true},
+			query: func(db *gorm.DB) error { return db.First(&loggedRow{}).Error },
+		},
+		{
+			name:  "record not found ignored but slow",
+			opts:  GormLoggerOptions{IgnoreRecordNotFoundError: true, SlowThreshold: time.Nanosecond},
+			query: func(db *gorm.DB) error { return db.First(&loggedRow{}).Error },
+			want:  []entry{{zapcore.WarnLevel, "gorm slow query"}},
+		},
+		{
+			name:  "other errors still logged",
+			opts:  GormLoggerOptions{IgnoreRecordNotFoundError: true},
+			query: func(db *gorm.DB) error { return db.Exec("SELECT * FROM missing").Error },
+			want:  []entry{{zapcore.ErrorLevel, "gorm query failed"}},
+		},
+		{
+			name:  "info level logs every query",
+			opts:  GormLoggerOptions{LogLevel: gormlogger.Info},
+			query: func(db *gorm.DB) error { return db.Exec("SELECT 1").Error },
+			want:  []entry{{zapcore.InfoLevel, "gorm query"}},
+		},
+		{
+			name:  "silent",
+			opts:  GormLoggerOptions{LogLevel: gormlogger.Silent, SlowThreshold: time.Nanosecond},
+			query: func(db *gorm.DB) error { return db.Exec("SELECT * FROM missing").Error },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, logs := openLoggedSQLite(t, tt.opts)
+			tt.query(db)
+
+			var got []entry
+			for _, e := range logs.AllUntimed() {
+				got = append(got, entry{e.Level, e.Message})
+				if _, ok := e.ContextMap()["sql"]; !ok {
+					t.Errorf("%s record has no sql field", e.Message)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("logged %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("record %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGormLoggerRecordNotFoundStillReturned(t *testing.T) {
+	db, _ := openLoggedSQLite(t, GormLoggerOptions{IgnoreRecordNotFoundError: true})
+	if err := db.First(&loggedRow{}).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("First() error = %v, want gorm.ErrRecordNotFound", err)
+	}
+}
@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/11SF/go-common/response"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// HealthCheckTimeout bounds each health check ping so a hung database
+// cannot hang a readiness probe.
+var HealthCheckTimeout = 2 * time.Second
+
+type HealthStatus struct {
+	Reachable       bool          `json:"reachable"`
+	Latency         time.Duration `json:"latency"`
+	OpenConnections int           `json:"open_connections"`
+	InUse           int           `json:"in_use"`
+	Idle            int           `json:"idle"`
+	WaitCount       int64         `json:"wait_count"`
+	WaitDuration    time.Duration `json:"wait_duration"`
+}
+
+// HealthCheck pings db within HealthCheckTimeout and reports pool stats.
+// The stats are filled in even when the ping fails.
+func HealthCheck(ctx context.Context, db *gorm.DB) (HealthStatus, error) {
+	if db == nil {
+		return HealthStatus{}, errors.New("postgres: nil database")
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return HealthStatus{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, HealthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err = sqlDB.PingContext(ctx)
+	latency := time.Since(start)
+
+	stats := sqlDB.Stats()
+	status := HealthStatus{
+		Reachable:       err == nil,
+		Latency:         latency,
+		OpenConnections: stats.OpenConnections,
+		InUse:           stats.InUse,
+		Idle:            stats.Idle,
+		WaitCount:       stats.WaitCount,
+		WaitDuration:    stats.WaitDuration,
+	}
+	return status, err
+}
+
+// GinHealthHandler responds 200 with the HealthStatus of db, or 503 with the
+// status and the ping error when the database is unreachable.
+func GinHealthHandler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status, err := HealthCheck(c.Request.Context(), db)
+		if err != nil {
+			response.NewGinResponseWithCode(c, http.StatusServiceUnavailable, response.GenericError, err.Error(), status)
+			return
+		}
+		response.NewGinResponse(c, http.StatusOK, status)
+	}
+}
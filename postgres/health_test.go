@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// openSQLite stands in for a Postgres handle; the health check only needs a
+// *gorm.DB backed by database/sql.
+func openSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "app.db")), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func closeDB(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.Close()
+}
+
+func TestHealthCheck(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		db      func(t *testing.T) *gorm.DB
+		ctx     context.Context
+		wantErr string
+	}{
+		{"healthy", openSQLite, context.Background(), ""},
+		{"closed", func(t *testing.T) *gorm.DB {
+			db := openSQLite(t)
+			closeDB(t, db)
+			return db
+		}, context.Background(), "sql: database is closed"},
+		{"cancelled context", openSQLite, cancelled, "context canceled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := HealthCheck(tt.ctx, tt.db(t))
+			if gotErr := errString(err); gotErr != tt.wantErr {
+				t.Fatalf("HealthCheck() error = %q, want %q", gotErr, tt.wantErr)
+			}
+			if status.Reachable != (tt.wantErr == "") {
+				t.Errorf("Reachable = %t, want %t", status.Reachable, tt.wantErr == "")
+			}
+			if tt.wantErr == "" && status.OpenConnections < 1 {
+				t.Errorf("OpenConnections = %d, want at least 1 after a ping", status.OpenConnections)
+			}
+		})
+	}
+
+	if _, err := HealthCheck(context.Background(), nil); err == nil {
+		t.Error("HealthCheck(nil) succeeded")
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func TestGinHealthHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	healthy := openSQLite(t)
+	closed := openSQLite(t)
+	closeDB(t, closed)
+
+	tests := []struct {
+		name       string
+		db         *gorm.DB
+		wantStatus int
+		wantCode   string
+	}{
+		{"healthy", healthy, http.StatusOK, "00000"},
+		{"closed", closed, http.StatusServiceUnavailable, "E9999"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodGet, "/health", nil)
+			GinHealthHandler(tt.db)(c)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			var body struct {
+				Code string       `json:"code"`
+				Data HealthStatus `json:"data"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if body.Code != tt.wantCode || body.Data.Reachable != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("body = %s, want code %s", rec.Body, tt.wantCode)
+			}
+		})
+	}
+}
@@ -1,12 +1,21 @@
 package postgres
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
+// DefaultTimeZone is used when Config.TimeZone is empty.
+const DefaultTimeZone = "Asia/Bangkok"
+
 type Config struct {
 	Host     string
 	Username string
@@ -15,13 +24,158 @@ type Config struct {
 	DBName   string
 	SSLMode  string
 	TimeZone string
+
+	// ApplicationName is reported in pg_stat_activity.
+	ApplicationName string
+	// ConnectTimeout is sent as connect_timeout, rounded up to whole seconds.
+	ConnectTimeout time.Duration
+	// SearchPath sets the schema search_path for new sessions.
+	SearchPath string
+	// Options is passed through as the libpq "options" parameter, e.g.
+	// "-c lock_timeout=5000".
+	Options string
+	// StatementTimeout is added to Options as "-c statement_timeout".
+	// PgBouncer in transaction mode rejects startup options unless listed in
+	// its ignore_startup_parameters; set it per role there instead.
+	StatementTimeout time.Duration
+	// PreferSimpleProtocol disables pgx's implicit prepared statements. Set
+	// it behind PgBouncer in transaction or statement pooling mode, where a
+	// prepared statement may be missing on the next server connection
+	// ("prepared statement does not exist"). Session pooling does not need it.
+	PreferSimpleProtocol bool
+	// ExtraParams are appended to the DSN in key order and quoted like the
+	// other values, e.g. {"target_session_attrs": "read-write"}.
+	ExtraParams map[string]string
+	// PasswordProvider, when set, is called for the password of each new
+	// connection instead of using Password, so rotating secrets and IAM
+	// tokens keep working. See PasswordProvider.
+	PasswordProvider PasswordProvider
 }
 
 func ConnectPostgres(cf *Config) (gorm.Dialector, error) {
-	if cf.TimeZone == "" {
-		cf.TimeZone = "Asia/bangkok"
+	dsn, err := BuildDSN(cf)
+	if err != nil {
+		return nil, err
+	}
+	if cf.PasswordProvider != nil {
+		return connectWithProvider(dsn, cf)
 	}
-	dsn := fmt.Sprintf("host=%v user=%v password=%v dbname=%v port=%v sslmode=%v TimeZone=%v", cf.Host, cf.Username, cf.Password, cf.DBName, cf.Port, cf.SSLMode, cf.TimeZone)
-	dial := postgres.Open(dsn)
+	dial := postgres.New(postgres.Config{
+		DSN:                  dsn,
+		PreferSimpleProtocol: cf.PreferSimpleProtocol,
+	})
 	return dial, nil
 }
+
+// BuildDSN builds a keyword/value connection string from cf, quoting and
+// escaping values per libpq rules so passwords with spaces, quotes or
+// backslashes survive. Host, Username and DBName are required.
+func BuildDSN(cf *Config) (string, error) {
+	if cf == nil {
+		return "", errors.New("postgres: nil config")
+	}
+
+	var missing []string
+	if cf.Host == "" {
+		missing = append(missing, "Host")
+	}
+	if cf.Username == "" {
+		missing = append(missing, "Username")
+	}
+	if cf.DBName == "" {
+		missing = append(missing, "DBName")
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("postgres: missing required config %s", strings.Join(missing, ", "))
+	}
+	if cf.Port < 0 || cf.Port > math.MaxUint16 {
+		return "", fmt.Errorf("postgres: invalid port %d", cf.Port)
+	}
+
+	timeZone := cf.TimeZone
+	if timeZone == "" {
+		timeZone = DefaultTimeZone
+	}
+
+	params := []struct {
+		key   string
+		value string
+	}{
+		{"host", cf.Host},
+		{"user", cf.Username},
+		{"password", cf.Password},
+		{"dbname", cf.DBName},
+		{"port", portString(cf.Port)},
+		{"sslmode", cf.SSLMode},
+		{"TimeZone", timeZone},
+		{"application_name", cf.ApplicationName},
+		{"connect_timeout", timeoutSeconds(cf.ConnectTimeout)},
+		{"search_path", cf.SearchPath},
+		{"options", dsnOptions(cf.Options, cf.StatementTimeout)},
+	}
+	extraKeys := make([]string, 0, len(cf.ExtraParams))
+	for key := range cf.ExtraParams {
+		extraKeys = append(extraKeys, key)
+	}
+	sort.Strings(extraKeys)
+	for _, key := range extraKeys {
+		params = append(params, struct {
+			key   string
+			value string
+		}{key, cf.ExtraParams[key]})
+	}
+
+	parts := make([]string, 0, len(params))
+	for _, param := range params {
+		if param.value == "" && param.key != "password" {
+			continue
+		}
+		parts = append(parts, param.key+"="+quoteDSNValue(param.value))
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// quoteDSNValue single-quotes value when it is empty or contains
+// whitespace, '=', quotes or backslashes, escaping ' and \ with a
+// backslash.
+func quoteDSNValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\n\r\v\f='\\") {
+		return value
+	}
+
+	var b strings.Builder
+	b.WriteByte('\'')
+	for _, r := range value {
+		if r == '\'' || r == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
+
+func dsnOptions(options string, statementTimeout time.Duration) string {
+	if statementTimeout <= 0 {
+		return options
+	}
+	timeout := fmt.Sprintf("-c statement_timeout=%d", statementTimeout.Milliseconds())
+	if options == "" {
+		return timeout
+	}
+	return options + " " + timeout
+}
+
+func portString(port int) string {
+	if port == 0 {
+		return ""
+	}
+	return strconv.Itoa(port)
+}
+
+func timeoutSeconds(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/driver/postgres"
+)
+
+func TestBuildDSN(t *testing.T) {
+	base := func() *Config {
+		return &Config{Host: "db.internal", Username: "app", DBName: "orders", Port: 5432}
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		want     string
+	}{
+		{"plain", "secret", "password=secret "},
+		{"empty", "", "password='' "},
+		{"spaces", "pass word ", `password='pass word ' `},
+		{"single quote", "it's", `password='it\'s' `},
+		{"double quote", `say "hi"`, `password='say "hi"' `},
+		{"backslash", `back\slash`, `password='back\\slash' `},
+		{"equals", "a=b", "password='a=b' "},
+		{"trailing backslash", `end\`, `password='end\\' `},
+		{"unicode", "pässwörd-密码-🔑", "password=pässwörd-密码-🔑 "},
+		{"unicode with space", "mật khẩu", "password='mật khẩu' "},
+		{"tab and newline", "a\tb\nc", "password='a\tb\nc' "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf := base()
+			cf.Password = tt.password
+
+			dsn, err := BuildDSN(cf)
+			if err != nil {
+				t.Fatalf("BuildDSN() error = %v", err)
+			}
+			if !strings.Contains(dsn, tt.want) {
+				t.Errorf("BuildDSN() = %q, want it to contain %q", dsn, tt.want)
+			}
+
+			parsed, err := pgx.ParseConfig(dsn)
+			if err != nil {
+				t.Fatalf("pgx.ParseConfig(%q) error = %v", dsn, err)
+			}
+			if parsed.Password != tt.password {
+				t.Errorf("parsed password = %q, want %q", parsed.Password, tt.password)
+			}
+			if parsed.Host != cf.Host || parsed.User != cf.Username || parsed.Database != cf.DBName || parsed.Port != 5432 {
+				t.Errorf("parsed = %s@%s:%d/%s, want app@db.internal:5432/orders", parsed.User, parsed.Host, parsed.Port, parsed.Database)
+			}
+		})
+	}
+}
+
+func TestBuildDSNOptionalFields(t *testing.T) {
+	cf := &Config{
+		Host:             "localhost",
+		Username:         "app",
+		Password:         "pw",
+		DBName:           "orders",
+		SSLMode:          "disable",
+		ApplicationName:  "order service",
+		ConnectTimeout:   1500 * time.Millisecond,
+		SearchPath:       "tenant_a,public",
+		Options:          "-c lock_timeout=5000",
+		StatementTimeout: 30 * time.Second,
+		ExtraParams:      map[string]string{"target_session_attrs": "read-write"},
+	}
+
+	dsn, err := BuildDSN(cf)
+	if err != nil {
+		t.Fatalf("BuildDSN() error = %v", err)
+	}
+	want := "host=localhost user=app password=pw dbname=orders sslmode=disable TimeZone=Asia/Bangkok " +
+		"application_name='order service' connect_timeout=2 search_path=tenant_a,public " +
+		"options='-c lock_timeout=5000 -c statement_timeout=30000' target_session_attrs=read-write"
+	if dsn != want {
+		t.Fatalf("BuildDSN() =\n%s\nwant\n%s", dsn, want)
+	}
+
+	parsed, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		t.Fatalf("pgx.ParseConfig() error = %v", err)
+	}
+	if parsed.ConnectTimeout != 2*time.Second {
+		t.Errorf("ConnectTimeout = %s, want 2s", parsed.ConnectTimeout)
+	}
+	for key, want := range map[string]string{
+		"TimeZone":         "Asia/Bangkok",
+		"application_name": "order service",
+		"search_path":      "tenant_a,public",
+		"options":          "-c lock_timeout=5000 -c statement_timeout=30000",
+	} {
+		if got := parsed.RuntimeParams[key]; got != want {
+			t.Errorf("RuntimeParams[%q] = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestBuildDSNValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		cf      *Config
+		wantErr string
+	}{
+		{"nil config", nil, "postgres: nil config"},
+		{"missing fields", &Config{Port: 5432}, "postgres: missing required config Host, Username, DBName"},
+		{"missing dbname", &Config{Host: "h", Username: "u"}, "postgres: missing required config DBName"},
+		{"negative port", &Config{Host: "h", Username: "u", DBName: "d", Port: -1}, "postgres: invalid port -1"},
+		{"port too large", &Config{Host: "h", Username: "u", DBName: "d", Port: 70000}, "postgres: invalid port 70000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := BuildDSN(tt.cf)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("BuildDSN() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConnectPostgresPreferSimpleProtocol(t *testing.T) {
+	provider := func(context.Context) (string, time.Time, error) { return "secret", time.Time{}, nil }
+
+	for _, prefer := range []bool{false, true} {
+		cf := &Config{Host: "db.internal", Username: "app", DBName: "orders", PreferSimpleProtocol: prefer}
+		dial, err := ConnectPostgres(cf)
+		if err != nil {
+			t.Fatalf("ConnectPostgres() error = %v", err)
+		}
+		pgDial, ok := dial.(*postgres.Dialector)
+		if !ok {
+			t.Fatalf("ConnectPostgres() = %T, want *postgres.Dialector", dial)
+		}
+		if pgDial.Config.PreferSimpleProtocol != prefer {
+			t.Errorf("PreferSimpleProtocol = %t, want %t", pgDial.Config.PreferSimpleProtocol, prefer)
+		}
+
+		// With a PasswordProvider the mode is set on the pgx config instead.
+		cf.PasswordProvider = provider
+		dial, err = ConnectPostgres(cf)
+		if err != nil {
+			t.Fatalf("ConnectPostgres() with provider error = %v", err)
+		}
+		want := pgx.QueryExecModeCacheStatement
+		if prefer {
+			want = pgx.QueryExecModeSimpleProtocol
+		}
+		if got := dial.(*providerDialector).connConfig.DefaultQueryExecMode; got != want {
+			t.Errorf("provider DefaultQueryExecMode = %v, want %v", got, want)
+		}
+	}
+}
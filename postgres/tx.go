@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+// WithGormTx runs fn inside a transaction and commits when it returns nil.
+// The transaction is rolled back when fn returns an error or panics; a
+// panic is re-raised after the rollback. opts may be nil.
+//
+// fn receives a ctx carrying the transaction. Calling WithGormTx again with
+// that ctx nests through a savepoint on the outer transaction, following
+// gorm's db.Transaction semantics: an inner failure rolls back to the
+// savepoint only, and nothing is committed until the outermost call
+// returns.
+func WithGormTx(ctx context.Context, db *gorm.DB, fn func(ctx context.Context, tx *gorm.DB) error, opts *sql.TxOptions) error {
+	if outer, ok := TxFromContext(ctx); ok {
+		db = outer
+	}
+
+	var txOpts []*sql.TxOptions
+	if opts != nil {
+		txOpts = append(txOpts, opts)
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx), tx)
+	}, txOpts...)
+}
+
+// TxFromContext returns the transaction started by WithGormTx, if any.
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	tx, ok := ctx.Value(txKey{}).(*gorm.DB)
+	return tx, ok
+}
@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func openTxSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := openSQLite(t)
+	if err := db.Exec("CREATE TABLE logged_rows (id INTEGER PRIMARY KEY, name TEXT)").Error; err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	return db
+}
+
+func insertRow(tx *gorm.DB, name string) error {
+	return tx.Create(&loggedRow{Name: name}).Error
+}
+
+func rowNames(t *testing.T, db *gorm.DB) []string {
+	t.Helper()
+	var names []string
+	if err := db.Model(&loggedRow{}).Order("id").Pluck("name", &names).Error; err != nil {
+		t.Fatalf("list rows: %v", err)
+	}
+	return names
+}
+
+func TestWithGormTx(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	t.Run("commit", func(t *testing.T) {
+		db := openTxSQLite(t)
+		err := WithGormTx(context.Background(), db, func(ctx context.Context, tx *gorm.DB) error {
+			if got, ok := TxFromContext(ctx); !ok || got != tx {
+				t.Error("TxFromContext() did not return the transaction")
+			}
+			return insertRow(tx, "a")
+		}, nil)
+		if err != nil {
+			t.Fatalf("WithGormTx() error = %v", err)
+		}
+		if got := rowNames(t, db); !slices.Equal(got, []string{"a"}) {
+			t.Errorf("rows = %v, want [a]", got)
+		}
+	})
+
+	t.Run("rollback on error", func(t *testing.T) {
+		db := openTxSQLite(t)
+		err := WithGormTx(context.Background(), db, func(ctx context.Context, tx *gorm.DB) error {
+			if err := insertRow(tx, "a"); err != nil {
+				return err
+			}
+			return errFailed
+		}, nil)
+		if !errors.Is(err, errFailed) {
+			t.Fatalf("WithGormTx() error = %v, want errFailed", err)
+		}
+		if got := rowNames(t, db); len(got) != 0 {
+			t.Errorf("rows = %v, want none", got)
+		}
+	})
+
+	t.Run("rollback on panic", func(t *testing.T) {
+		db := openTxSQLite(t)
+		func() {
+			defer func() {
+				if recovered := recover(); recovered != "boom" {
+					t.Errorf("recovered %v, want the re-raised panic", recovered)
+				}
+			}()
+			WithGormTx(context.Background(), db, func(ctx context.Context, tx *gorm.DB) error {
+				insertRow(tx, "a")
+				panic("boom")
+			}, nil)
+		}()
+		if got := rowNames(t, db); len(got) != 0 {
+			t.Errorf("rows = %v, want none", got)
+		}
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		db := openTxSQLite(t)
+		err := WithGormTx(context.Background(), db, func(ctx context.Context, tx *gorm.DB) error {
+			if err := insertRow(tx, "outer"); err != nil {
+				return err
+			}
+			if err := WithGormTx(ctx, db, func(ctx context.Context, tx *gorm.DB) error {
+				return insertRow(tx, "kept")
+			}, nil); err != nil {
+				return err
+			}
+			// An inner failure rolls back to its savepoint only.
+			err := WithGormTx(ctx, db, func(ctx context.Context, tx *gorm.DB) error {
+				if err := insertRow(tx, "dropped"); err != nil {
+					return err
+				}
+				return errFailed
+			}, nil)
+			if !errors.Is(err, errFailed) {
+				t.Errorf("inner WithGormTx() error = %v, want errFailed", err)
+			}
+			return nil
+		}, nil)
+		if err != nil {
+			t.Fatalf("WithGormTx() error = %v", err)
+		}
+		if got := rowNames(t, db); !slices.Equal(got, []string{"outer", "kept"}) {
+			t.Errorf("rows = %v, want [outer kept]", got)
+		}
+	})
+
+	t.Run("outer rollback discards nested commits", func(t *testing.T) {
+		db := openTxSQLite(t)
+		err := WithGormTx(context.Background(), db, func(ctx context.Context, tx *gorm.DB) error {
+			if err := WithGormTx(ctx, db, func(ctx context.Context, tx *gorm.DB) error {
+				return insertRow(tx, "inner")
+			}, nil); err != nil {
+				return err
+			}
+			return errFailed
+		}, nil)
+		if !errors.Is(err, errFailed) {
+			t.Fatalf("WithGormTx() error = %v, want errFailed", err)
+		}
+		if got := rowNames(t, db); len(got) != 0 {
+			t.Errorf("rows = %v, want none", got)
+		}
+	})
+
+	if _, ok := TxFromContext(context.Background()); ok {
+		t.Error("TxFromContext() found a transaction in a plain context")
+	}
+}
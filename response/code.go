@@ -1,5 +1,10 @@
 package response
 
+import (
+	"net/http"
+	"sync"
+)
+
 type ResponseCode string
 
 var (
@@ -10,3 +15,49 @@ var (
 
 	GenericError ResponseCode = "E9999"
 )
+
+type codeInfo struct {
+	status  int
+	message string
+}
+
+var (
+	codesMu sync.RWMutex
+	codes   = map[ResponseCode]codeInfo{
+		SuccessCode:     {status: http.StatusOK, message: "Success"},
+		BadRequestCode:  {status: http.StatusBadRequest, message: "Bad request"},
+		UnAuthorizeCode: {status: http.StatusUnauthorized, message: "Unauthorized"},
+		NotFoundCode:    {status: http.StatusNotFound, message: "Not found"},
+		GenericError:    {status: http.StatusInternalServerError, message: "Internal server error"},
+	}
+)
+
+// RegisterCode maps code to an HTTP status and default message, replacing
+// any previous registration. It is safe for concurrent use.
+func RegisterCode(code ResponseCode, status int, defaultMessage string) {
+	codesMu.Lock()
+	defer codesMu.Unlock()
+	codes[code] = codeInfo{status: status, message: defaultMessage}
+}
+
+// HTTPStatus returns the HTTP status registered for code, or 500 for
+// unknown codes.
+func HTTPStatus(code ResponseCode) int {
+	codesMu.RLock()
+	defer codesMu.RUnlock()
+	if info, ok := codes[code]; ok {
+		return info.status
+	}
+	return http.StatusInternalServerError
+}
+
+// DefaultMessage returns the message registered for code, or the
+// GenericError message for unknown codes.
+func DefaultMessage(code ResponseCode) string {
+	codesMu.RLock()
+	defer codesMu.RUnlock()
+	if info, ok := codes[code]; ok {
+		return info.message
+	}
+	return codes[GenericError].message
+}
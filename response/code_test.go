@@ -0,0 +1,89 @@
+package response
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// resetCodes restores the code registry after the test.
+func resetCodes(t *testing.T) {
+	t.Helper()
+	codesMu.Lock()
+	prev := maps.Clone(codes)
+	codesMu.Unlock()
+	t.Cleanup(func() {
+		codesMu.Lock()
+		codes = prev
+		codesMu.Unlock()
+	})
+}
+
+func TestRegisterCode(t *testing.T) {
+	resetCodes(t)
+	RegisterCode("E1001", http.StatusConflict, "Order already paid")
+	RegisterCode(NotFoundCode, http.StatusGone, "Gone")
+
+	tests := []struct {
+		code        ResponseCode
+		wantStatus  int
+		wantMessage string
+	}{
+		{SuccessCode, http.StatusOK, "Success"},
+		{BadRequestCode, http.StatusBadRequest, "Bad request"},
+		{"E1001", http.StatusConflict, "Order already paid"},
+		{NotFoundCode, http.StatusGone, "Gone"},
+		{"E7777", http.StatusInternalServerError, "Internal server error"},
+		{"", http.StatusInternalServerError, "Internal server error"},
+	}
+
+	for _, tt := range tests {
+		if got := HTTPStatus(tt.code); got != tt.wantStatus {
+			t.Errorf("HTTPStatus(%q) = %d, want %d", tt.code, got, tt.wantStatus)
+		}
+		if got := DefaultMessage(tt.code); got != tt.wantMessage {
+			t.Errorf("DefaultMessage(%q) = %q, want %q", tt.code, got, tt.wantMessage)
+		}
+	}
+
+	// The registration reaches the framework helpers.
+	res := serveGin(t, httptest.NewRequest(http.MethodGet, "/", nil), func(c *gin.Context) { NewGinError(c, "E1001", "") })
+	if res.status != http.StatusConflict || res.body != `{"code":"E1001","message":"Order already paid"}` {
+		t.Errorf("NewGinError() = %d %s, want 409 with the registered message", res.status, res.body)
+	}
+}
+
+// Run with -race: registrations racing with lookups.
+func TestRegisterCodeConcurrent(t *testing.T) {
+	resetCodes(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			RegisterCode(ResponseCode(fmt.Sprintf("C%04d", i)), 400+i, fmt.Sprintf("message %d", i))
+		}()
+		go func() {
+			defer wg.Done()
+			HTTPStatus(BadRequestCode)
+			DefaultMessage(ResponseCode(fmt.Sprintf("C%04d", i)))
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < 20; i++ {
+		code := ResponseCode(fmt.Sprintf("C%04d", i))
+		if got := HTTPStatus(code); got != 400+i {
+			t.Errorf("HTTPStatus(%s) = %d, want %d", code, got, 400+i)
+		}
+		if got, want := DefaultMessage(code), fmt.Sprintf("message %d", i); got != want {
+			t.Errorf("DefaultMessage(%s) = %q, want %q", code, got, want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package response
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type fileOptions struct {
+	inline bool
+	log    *zap.Logger
+}
+
+// FileOption customizes a file response.
+type FileOption func(*fileOptions)
+
+// FileInline asks the browser to display the file instead of downloading it.
+func FileInline() FileOption {
+	return func(o *fileOptions) {
+		o.inline = true
+	}
+}
+
+// FileLogger logs errors that happen after the response has started.
+func FileLogger(log *zap.Logger) FileOption {
+	return func(o *fileOptions) {
+		o.log = log
+	}
+}
+
+func newFileOptions(opts []FileOption) fileOptions {
+	var o fileOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// NewGinFileResponse streams r as a file download. size sets Content-Length
+// when it is 0 or more. Once streaming has started a read error can only be
+// logged, so the response is aborted instead of writing an error envelope
+// over partially sent bytes.
+func NewGinFileResponse(c *gin.Context, filename string, contentType string, r io.Reader, size int64, opts ...FileOption) {
+	o := newFileOptions(opts)
+
+	c.Header("Content-Disposition", ContentDisposition(filename, o.inline))
+	c.Header("Content-Type", contentType)
+	if size >= 0 {
+		c.Header("Content-Length", strconv.FormatInt(size, 10))
+	}
+	c.Status(http.StatusOK)
+
+	if _, err := io.Copy(c.Writer, r); err != nil {
+		logStreamError(o.log, filename, err)
+		c.Abort()
+	}
+}
+
+// NewFiberFileResponse streams r as a file download. size sets
+// Content-Length when it is 0 or more; fiber sends the body after the
+// handler returns, so read errors are logged rather than returned.
+func NewFiberFileResponse(c *fiber.Ctx, filename string, contentType string, r io.Reader, size int64, opts ...FileOption) error {
+	o := newFileOptions(opts)
+
+	c.Set(fiber.HeaderContentDisposition, ContentDisposition(filename, o.inline))
+	c.Set(fiber.HeaderContentType, contentType)
+	if size < 0 {
+		size = -1
+	}
+	return c.Status(http.StatusOK).SendStream(&loggingReader{r: r, filename: filename, log: o.log}, int(size))
+}
+
+// ContentDisposition builds a Content-Disposition header value with an
+// ASCII filename fallback and an RFC 5987 encoded filename* for non-ASCII
+// names.
+func ContentDisposition(filename string, inline bool) string {
+	disposition := "attachment"
+	if inline {
+		disposition = "inline"
+	}
+
+	fallback, ascii := asciiFilename(filename)
+	if ascii {
+		return fmt.Sprintf(`%s; filename="%s"`, disposition, fallback)
+	}
+	return fmt.Sprintf(`%s; filename="%s"; filename*=UTF-8''%s`, disposition, fallback, encodeRFC5987(filename))
+}
+
+// asciiFilename replaces characters that cannot appear in a quoted ASCII
+// filename with "_" and reports whether none had to be replaced.
+func asciiFilename(filename string) (string, bool) {
+	ascii := true
+	fallback := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			ascii = false
+			return '_'
+		}
+		return r
+	}, filename)
+	return fallback, ascii
+}
+
+func encodeRFC5987(s string) string {
+	const hex = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isAttrChar(c) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0x0f])
+	}
+	return b.String()
+}
+
+func isAttrChar(c byte) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", c) >= 0
+}
+
+// loggingReader logs the first read error other than io.EOF.
+type loggingReader struct {
+	r        io.Reader
+	filename string
+	log      *zap.Logger
+	logged   bool
+}
+
+func (lr *loggingReader) Read(p []byte) (int, error) {
+	n, err := lr.r.Read(p)
+	if err != nil && err != io.EOF && !lr.logged {
+		lr.logged = true
+		logStreamError(lr.log, lr.filename, err)
+	}
+	return n, err
+}
+
+// Close closes the wrapped reader when it is an io.Closer, as fasthttp
+// would have done for it.
+func (lr *loggingReader) Close() error {
+	if closer, ok := lr.r.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func logStreamError(log *zap.Logger, filename string, err error) {
+	if log != nil {
+		log.Error("file response interrupted", zap.String("filename", filename), zap.Error(err))
+	}
+}
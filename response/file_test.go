@@ -0,0 +1,110 @@
+package response
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestContentDisposition(t *testing.T) {
+	tests := []struct {
+		filename string
+		inline   bool
+		want     string
+	}{
+		{"report.pdf", false, `attachment; filename="report.pdf"`},
+		{"report.pdf", true, `inline; filename="report.pdf"`},
+		{"ใบเสร็จ.pdf", false, `attachment; filename="_______.pdf"; filename*=UTF-8''%E0%B9%83%E0%B8%9A%E0%B9%80%E0%B8%AA%E0%B8%A3%E0%B9%87%E0%B8%88.pdf`},
+		{`a "quoted" name.txt`, false, `attachment; filename="a _quoted_ name.txt"; filename*=UTF-8''a%20%22quoted%22%20name.txt`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			if got := ContentDisposition(tt.filename, tt.inline); got != tt.want {
+				t.Errorf("ContentDisposition() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileResponse(t *testing.T) {
+	payload := make([]byte, 1<<20)
+	rand.New(rand.NewSource(1)).Read(payload)
+	const filename = "รายงาน.csv"
+	wantDisposition := ContentDisposition(filename, false)
+
+	tests := []struct {
+		name string
+		size int64
+	}{
+		{"known size", int64(len(payload))},
+		{"unknown size", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := map[string]served{
+				"gin": serveGin(t, httptest.NewRequest(http.MethodGet, "/", nil), func(c *gin.Context) {
+					NewGinFileResponse(c, filename, "text/csv", bytes.NewReader(payload), tt.size)
+				}),
+				"fiber": serveFiber(t, httptest.NewRequest(http.MethodGet, "/", nil), func(c *fiber.Ctx) error {
+					return NewFiberFileResponse(c, filename, "text/csv", bytes.NewReader(payload), tt.size)
+				}),
+			}
+			for name, res := range got {
+				if res.status != http.StatusOK {
+					t.Errorf("%s status = %d, want 200", name, res.status)
+				}
+				if got := res.header.Get("Content-Disposition"); got != wantDisposition {
+					t.Errorf("%s Content-Disposition = %s, want %s", name, got, wantDisposition)
+				}
+				if got := res.header.Get("Content-Type"); got != "text/csv" {
+					t.Errorf("%s Content-Type = %s, want text/csv", name, got)
+				}
+				if tt.size >= 0 && res.header.Get("Content-Length") != strconv.FormatInt(tt.size, 10) {
+					t.Errorf("%s Content-Length = %q, want %d", name, res.header.Get("Content-Length"), tt.size)
+				}
+				if res.body != string(payload) {
+					t.Errorf("%s body has %d bytes that differ from the %d byte payload", name, len(res.body), len(payload))
+				}
+			}
+		})
+	}
+}
+
+type failingReader struct{ err error }
+
+func (r failingReader) Read([]byte) (int, error) { return 0, r.err }
+
+func TestGinFileResponseReadError(t *testing.T) {
+	log, logs := observedLogger()
+	errDisk := errors.New("disk read failed")
+	r := io.MultiReader(bytes.NewReader([]byte("partial")), failingReader{errDisk})
+
+	var aborted bool
+	res := serveGin(t, httptest.NewRequest(http.MethodGet, "/", nil), func(c *gin.Context) {
+		NewGinFileResponse(c, "a.txt", "text/plain", r, -1, FileInline(), FileLogger(log))
+		aborted = c.IsAborted()
+	})
+	if !aborted {
+		t.Error("context not aborted after a read error")
+	}
+	if res.body != "partial" {
+		t.Errorf("body = %q, want only the bytes read before the error", res.body)
+	}
+	if got := res.header.Get("Content-Disposition"); got != `inline; filename="a.txt"` {
+		t.Errorf("Content-Disposition = %s, want inline", got)
+	}
+	entries := logs.FilterMessage("file response interrupted").AllUntimed()
+	if len(entries) != 1 || entries[0].ContextMap()["filename"] != "a.txt" {
+		t.Errorf("logged %v, want one interrupted entry for a.txt", entries)
+	}
+}
@@ -0,0 +1,67 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+const contentTypeJSON = "application/json; charset=utf-8"
+
+// fallbackErrorBody is written when encoding a response fails.
+var fallbackErrorBody = []byte(`{"code":"E9999","message":"Internal server error"}`)
+
+// WriteJSON writes the success envelope for data to a plain net/http
+// response.
+func WriteJSON[T any](w http.ResponseWriter, status int, data T, opts ...MetaOption) error {
+	return writeEnvelope(w, status, newSuccess(SuccessCode, "", data, opts))
+}
+
+// WriteError writes the error envelope for err. A status of 0 uses the
+// status registered for the error's code.
+func WriteError(w http.ResponseWriter, status int, err error) error {
+	errRes := toResponseError(err)
+	if status <= 0 {
+		status = HTTPStatus(errRes.Code)
+	}
+	return writeEnvelope(w, status, errRes)
+}
+
+// Render writes data as WriteJSON does after checking the request's Accept
+// header. JSON is the only representation for now; clients that do not
+// accept it get 406.
+func Render(w http.ResponseWriter, r *http.Request, status int, data interface{}, opts ...MetaOption) error {
+	if !acceptsJSON(r.Header.Get("Accept")) {
+		return WriteError(w, http.StatusNotAcceptable, NewError(GenericError, "Not acceptable"))
+	}
+	return WriteJSON(w, status, data, opts...)
+}
+
+func writeEnvelope(w http.ResponseWriter, status int, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		w.Header().Set("Content-Type", contentTypeJSON)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(fallbackErrorBody)
+		return err
+	}
+
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.WriteHeader(status)
+	_, err = w.Write(data)
+	return err
+}
+
+func acceptsJSON(accept string) bool {
+	if accept == "" {
+		return true
+	}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		switch mediaType {
+		case "*/*", "application/*", "application/json":
+			return true
+		}
+	}
+	return false
+}
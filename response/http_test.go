@@ -0,0 +1,90 @@
+package response
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteJSON(t *testing.T) {
+	rec := httptest.NewRecorder()
+	if err := WriteJSON(rec, http.StatusCreated, map[string]int{"id": 7}, WithMeta("request_id", "r1")); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	assertRecorded(t, rec, http.StatusCreated, `{"code":"00000","message":"","data":{"id":7},"meta":{"request_id":"r1"}}`)
+}
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		err    error
+		want   int
+		body   string
+	}{
+		{"registered status", 0, NewError(NotFoundCode, "order not found"), http.StatusNotFound, `{"code":"E0404","message":"order not found"}`},
+		{"explicit status", http.StatusConflict, NewError(BadRequestCode, "dup"), http.StatusConflict, `{"code":"E0400","message":"dup"}`},
+		{"unknown error", -1, errors.New("secret"), http.StatusInternalServerError, `{"code":"E9999","message":"Internal server error"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			if err := WriteError(rec, tt.status, tt.err); err != nil {
+				t.Fatalf("WriteError() error = %v", err)
+			}
+			assertRecorded(t, rec, tt.want, tt.body)
+		})
+	}
+}
+
+func TestWriteJSONEncodeFailure(t *testing.T) {
+	rec := httptest.NewRecorder()
+	err := WriteJSON(rec, http.StatusOK, func() {})
+	if err == nil {
+		t.Fatal("WriteJSON() of a func succeeded")
+	}
+	assertRecorded(t, rec, http.StatusInternalServerError, string(fallbackErrorBody))
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		accept string
+		status int
+		body   string
+	}{
+		{"", http.StatusOK, `{"code":"00000","message":"","data":"x"}`},
+		{"application/json", http.StatusOK, `{"code":"00000","message":"","data":"x"}`},
+		{"text/html, application/json;q=0.9", http.StatusOK, `{"code":"00000","message":"","data":"x"}`},
+		{"application/*", http.StatusOK, `{"code":"00000","message":"","data":"x"}`},
+		{"*/*", http.StatusOK, `{"code":"00000","message":"","data":"x"}`},
+		{"text/html", http.StatusNotAcceptable, `{"code":"E9999","message":"Not acceptable"}`},
+		{"application/xml", http.StatusNotAcceptable, `{"code":"E9999","message":"Not acceptable"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+			if err := Render(rec, req, http.StatusOK, "x"); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			assertRecorded(t, rec, tt.status, tt.body)
+		})
+	}
+}
+
+func assertRecorded(t *testing.T, rec *httptest.ResponseRecorder, status int, body string) {
+	t.Helper()
+	if rec.Code != status {
+		t.Errorf("status = %d, want %d", rec.Code, status)
+	}
+	if got := rec.Header().Get("Content-Type"); got != contentTypeJSON {
+		t.Errorf("Content-Type = %q, want %q", got, contentTypeJSON)
+	}
+	if got := rec.Body.String(); got != body {
+		t.Errorf("body = %s, want %s", got, body)
+	}
+}
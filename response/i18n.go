@@ -0,0 +1,155 @@
+package response
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofiber/fiber/v2"
+	"github.com/labstack/echo/v4"
+)
+
+// LanguageKey is the gin/echo context key and fiber local that, when set to
+// a language tag, takes precedence over the Accept-Language header.
+const LanguageKey = "response.language"
+
+var (
+	catalogMu       sync.RWMutex
+	catalog         = map[string]map[ResponseCode]string{}
+	defaultLanguage = "en"
+)
+
+// RegisterMessages adds translations for lang, merging with any already
+// registered. It is safe for concurrent use.
+func RegisterMessages(lang string, messages map[ResponseCode]string) {
+	lang = normalizeLanguage(lang)
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	if catalog[lang] == nil {
+		catalog[lang] = make(map[ResponseCode]string, len(messages))
+	}
+	for code, message := range messages {
+		catalog[lang][code] = message
+	}
+}
+
+// SetDefaultLanguage sets the language tried after the requested ones.
+// It defaults to "en".
+func SetDefaultLanguage(lang string) {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	defaultLanguage = normalizeLanguage(lang)
+}
+
+// Localize returns the message registered for code in the first of langs
+// that has one, then in the default language, and finally message itself.
+func Localize(code ResponseCode, message string, langs ...string) string {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	for _, lang := range langs {
+		if translated, ok := catalog[normalizeLanguage(lang)][code]; ok {
+			return translated
+		}
+	}
+	if translated, ok := catalog[defaultLanguage][code]; ok {
+		return translated
+	}
+	return message
+}
+
+// ParseAcceptLanguage returns the languages of an Accept-Language header
+// ordered by quality, dropping those with q=0. A regional tag such as
+// "th-TH" is followed by its base language "th".
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+
+	var entries []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		lang := normalizeLanguage(fields[0])
+		if lang == "" || lang == "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				parsed, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+				if err != nil {
+					parsed = 0
+				}
+				q = parsed
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		entries = append(entries, weighted{lang: lang, q: q})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].q > entries[j].q
+	})
+
+	langs := make([]string, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+	add := func(lang string) {
+		if !seen[lang] {
+			seen[lang] = true
+			langs = append(langs, lang)
+		}
+	}
+	for _, entry := range entries {
+		add(entry.lang)
+		if base, _, ok := strings.Cut(entry.lang, "-"); ok {
+			add(base)
+		}
+	}
+	return langs
+}
+
+func normalizeLanguage(lang string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))
+}
+
+// localizeError returns errRes with its message translated for langs. The
+// original error is never modified.
+func localizeError(errRes *ResponseError, langs []string) *ResponseError {
+	message := Localize(errRes.Code, errRes.Message, langs...)
+	if message == errRes.Message {
+		return errRes
+	}
+	return &ResponseError{
+		Code:    errRes.Code,
+		Message: message,
+		cause:   errRes.cause,
+	}
+}
+
+func ginLanguages(c *gin.Context) []string {
+	if lang := c.GetString(LanguageKey); lang != "" {
+		return []string{lang}
+	}
+	return ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+}
+
+func echoLanguages(c echo.Context) []string {
+	if lang, ok := c.Get(LanguageKey).(string); ok && lang != "" {
+		return []string{lang}
+	}
+	return ParseAcceptLanguage(c.Request().Header.Get("Accept-Language"))
+}
+
+func fiberLanguages(c *fiber.Ctx) []string {
+	if lang, ok := c.Locals(LanguageKey).(string); ok && lang != "" {
+		return []string{lang}
+	}
+	return ParseAcceptLanguage(c.Get(fiber.HeaderAcceptLanguage))
+}
@@ -0,0 +1,168 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// resetCatalog empties the message catalog for the test and restores it
+// afterwards.
+func resetCatalog(t *testing.T) {
+	t.Helper()
+	catalogMu.Lock()
+	prevCatalog, prevDefault := catalog, defaultLanguage
+	catalog, defaultLanguage = map[string]map[ResponseCode]string{}, "en"
+	catalogMu.Unlock()
+	t.Cleanup(func() {
+		catalogMu.Lock()
+		catalog, defaultLanguage = prevCatalog, prevDefault
+		catalogMu.Unlock()
+	})
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{"", []string{}},
+		{"th", []string{"th"}},
+		{"th-TH,th;q=0.9,en;q=0.8", []string{"th-th", "th", "en"}},
+		{"en;q=0.5, th;q=0.9", []string{"th", "en"}},
+		{"en-US;q=0.7,th_TH", []string{"th-th", "th", "en-us", "en"}},
+		{"fr;q=0.8,de;q=0.8,en", []string{"en", "fr", "de"}},
+		{"en;q=0,th", []string{"th"}},
+		{"*;q=0.5,th", []string{"th"}},
+		{"th;q=abc,en", []string{"en"}},
+		{" th ; q=0.3 , , en ; q=0.4 ", []string{"en", "th"}},
+		{"th,th-TH;q=0.5", []string{"th", "th-th"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := ParseAcceptLanguage(tt.header); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseAcceptLanguage(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	resetCatalog(t)
+	RegisterMessages("en", map[ResponseCode]string{
+		BadRequestCode: "Bad request",
+		NotFoundCode:   "Not found",
+	})
+	RegisterMessages("TH", map[ResponseCode]string{
+		BadRequestCode: "คำขอไม่ถูกต้อง",
+	})
+	RegisterMessages("th-TH", map[ResponseCode]string{
+		UnAuthorizeCode: "ไม่ได้รับอนุญาต",
+	})
+
+	tests := []struct {
+		name  string
+		code  ResponseCode
+		langs []string
+		want  string
+	}{
+		{"first language", BadRequestCode, []string{"th", "en"}, "คำขอไม่ถูกต้อง"},
+		{"regional tag", UnAuthorizeCode, []string{"th-TH", "th"}, "ไม่ได้รับอนุญาต"},
+		{"per-code fallback to next language", NotFoundCode, []string{"th", "en"}, "Not found"},
+		{"default language", NotFoundCode, []string{"th"}, "Not found"},
+		{"unknown language", BadRequestCode, []string{"fr"}, "Bad request"},
+		{"raw message", GenericError, []string{"th"}, "raw"},
+		{"no languages", BadRequestCode, nil, "Bad request"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Localize(tt.code, "raw", tt.langs...); got != tt.want {
+				t.Errorf("Localize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	SetDefaultLanguage("th")
+	if got := Localize(BadRequestCode, "raw", "fr"); got != "คำขอไม่ถูกต้อง" {
+		t.Errorf("Localize() with default th = %q", got)
+	}
+}
+
+func TestGinErrorLanguage(t *testing.T) {
+	resetCatalog(t)
+	RegisterMessages("th", map[ResponseCode]string{BadRequestCode: "คำขอไม่ถูกต้อง"})
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		header   string
+		explicit string
+		want     string
+	}{
+		{"accept-language", "en;q=0.5,th;q=0.9", "", "คำขอไม่ถูกต้อง"},
+		{"no translation", "en", "", "invalid id"},
+		{"explicit language wins", "th", "en", "invalid id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			c.Request.Header.Set("Accept-Language", tt.header)
+			if tt.explicit != "" {
+				c.Set(LanguageKey, tt.explicit)
+			}
+
+			err := NewError(BadRequestCode, "invalid id")
+			NewGinResponseError(c, http.StatusBadRequest, err)
+
+			var body ResponseError
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if body.Message != tt.want {
+				t.Errorf("message = %q, want %q", body.Message, tt.want)
+			}
+			var original *ResponseError
+			if !errors.As(err, &original) || original.Message != "invalid id" {
+				t.Errorf("original error changed to %v", err)
+			}
+		})
+	}
+}
+
+func TestRegisterMessagesConcurrent(t *testing.T) {
+	resetCatalog(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			code := ResponseCode(fmt.Sprintf("T%04d", i))
+			RegisterMessages("th", map[ResponseCode]string{code: fmt.Sprintf("th %d", i)})
+		}()
+		go func() {
+			defer wg.Done()
+			Localize(BadRequestCode, "raw", "th", "en")
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < 20; i++ {
+		code := ResponseCode(fmt.Sprintf("T%04d", i))
+		if got, want := Localize(code, "raw", "th"), fmt.Sprintf("th %d", i); got != want {
+			t.Errorf("Localize(%s) = %q, want %q", code, got, want)
+		}
+	}
+}
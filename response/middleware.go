@@ -0,0 +1,153 @@
+package response
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofiber/fiber/v2"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// panicError is the body sent when a handler panics.
+func panicError() *ResponseError {
+	return newCodeError(GenericError, "")
+}
+
+// codeForStatus picks a built-in code for a framework error status.
+func codeForStatus(status int) ResponseCode {
+	switch status {
+	case http.StatusBadRequest:
+		return BadRequestCode
+	case http.StatusUnauthorized:
+		return UnAuthorizeCode
+	case http.StatusNotFound:
+		return NotFoundCode
+	default:
+		return GenericError
+	}
+}
+
+func logPanic(log *zap.Logger, recovered interface{}, method, path string) {
+	if log == nil {
+		return
+	}
+	log.Error("recovered from panic",
+		zap.Any("panic", recovered),
+		zap.String("method", method),
+		zap.String("path", path),
+		zap.ByteString("stack", debug.Stack()),
+	)
+}
+
+// GinRecoverMiddleware recovers handler panics, logs them to log when it is
+// not nil and responds 500 with the standard error envelope.
+func GinRecoverMiddleware(log *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logPanic(log, recovered, c.Request.Method, c.Request.URL.Path)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, localizeError(panicError(), ginLanguages(c)))
+			}
+		}()
+		c.Next()
+	}
+}
+
+// EchoRecoverMiddleware recovers handler panics, logs them to log when it is
+// not nil and responds 500 with the standard error envelope.
+func EchoRecoverMiddleware(log *zap.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					logPanic(log, recovered, c.Request().Method, c.Request().URL.Path)
+					err = c.JSON(http.StatusInternalServerError, localizeError(panicError(), echoLanguages(c)))
+				}
+			}()
+			return next(c)
+		}
+	}
+}
+
+// FiberRecoverMiddleware recovers handler panics, logs them to log when it
+// is not nil and responds 500 with the standard error envelope.
+func FiberRecoverMiddleware(log *zap.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logPanic(log, recovered, c.Method(), c.Path())
+				err = c.Status(http.StatusInternalServerError).JSON(localizeError(panicError(), fiberLanguages(c)))
+			}
+		}()
+		return c.Next()
+	}
+}
+
+// EchoErrorHandler renders errors returned by handlers with the standard
+// envelope. *echo.HTTPError keeps its status; other errors use the status
+// registered for their code. Server errors are logged to log when it is not
+// nil.
+func EchoErrorHandler(log *zap.Logger) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+
+		status, errRes := frameworkError(err)
+		logServerError(log, status, err)
+		if c.Request().Method == http.MethodHead {
+			c.NoContent(status)
+			return
+		}
+		c.JSON(status, localizeError(errRes, echoLanguages(c)))
+	}
+}
+
+// FiberErrorHandler renders errors returned by handlers with the standard
+// envelope, for use as fiber.Config.ErrorHandler. *fiber.Error keeps its
+// status; other errors use the status registered for their code. Server
+// errors are logged to log when it is not nil.
+func FiberErrorHandler(log *zap.Logger) fiber.ErrorHandler {
+	return func(c *fiber.Ctx, err error) error {
+		status, errRes := frameworkError(err)
+		logServerError(log, status, err)
+		return c.Status(status).JSON(localizeError(errRes, fiberLanguages(c)))
+	}
+}
+
+// frameworkError maps framework HTTP errors and *ResponseError values to a
+// status and envelope. Messages of unknown errors are not exposed.
+func frameworkError(err error) (int, *ResponseError) {
+	var echoErr *echo.HTTPError
+	if errors.As(err, &echoErr) {
+		return echoErr.Code, &ResponseError{
+			Code:    codeForStatus(echoErr.Code),
+			Message: fmt.Sprint(echoErr.Message),
+		}
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code, &ResponseError{
+			Code:    codeForStatus(fiberErr.Code),
+			Message: fiberErr.Message,
+		}
+	}
+
+	var errRes *ResponseError
+	if errors.As(err, &errRes) {
+		return HTTPStatus(errRes.Code), errRes
+	}
+
+	return http.StatusInternalServerError, newCodeError(GenericError, "")
+}
+
+func logServerError(log *zap.Logger, status int, err error) {
+	if log != nil && status >= http.StatusInternalServerError {
+		log.Error("request failed", zap.Int("status", status), zap.Error(err))
+	}
+}
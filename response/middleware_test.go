@@ -0,0 +1,174 @@
+package response
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofiber/fiber/v2"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func observedLogger() (*zap.Logger, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	return zap.New(core), logs
+}
+
+func TestRecoverMiddleware(t *testing.T) {
+	const want = `{"code":"E9999","message":"Internal server error"}`
+	log, logs := observedLogger()
+
+	gin.SetMode(gin.TestMode)
+	g := gin.New()
+	g.Use(GinRecoverMiddleware(log))
+	g.GET("/panic", func(*gin.Context) { panic("boom") })
+
+	e := echo.New()
+	e.Use(EchoRecoverMiddleware(log))
+	e.GET("/panic", func(echo.Context) error { panic("boom") })
+
+	f := fiber.New()
+	f.Use(FiberRecoverMiddleware(log))
+	f.Get("/panic", func(*fiber.Ctx) error { panic("boom") })
+
+	got := map[string]served{
+		"gin":   serveRouter(t, g, http.MethodGet, "/panic"),
+		"echo":  serveRouter(t, e, http.MethodGet, "/panic"),
+		"fiber": serveFiberApp(t, f, http.MethodGet, "/panic"),
+	}
+	for name, res := range got {
+		if res.status != http.StatusInternalServerError || res.body != want {
+			t.Errorf("%s = %d %s, want 500 %s", name, res.status, res.body, want)
+		}
+	}
+
+	entries := logs.FilterMessage("recovered from panic").AllUntimed()
+	if len(entries) != len(got) {
+		t.Fatalf("logged %d panics, want %d", len(entries), len(got))
+	}
+	for _, entry := range entries {
+		fields := entry.ContextMap()
+		if fields["panic"] != "boom" || fields["path"] != "/panic" || fields["method"] != http.MethodGet {
+			t.Errorf("panic log fields = %v", fields)
+		}
+	}
+}
+
+func TestFrameworkErrorHandlers(t *testing.T) {
+	tests := []struct {
+		name     string
+		echoErr  error
+		fiberErr error
+		status   int
+		body     string
+		logged   bool
+	}{
+		{
+			name:     "framework not found",
+			echoErr:  echo.NewHTTPError(http.StatusNotFound, "missing"),
+			fiberErr: fiber.NewError(http.StatusNotFound, "missing"),
+			status:   http.StatusNotFound,
+			body:     `{"code":"E0404","message":"missing"}`,
+		},
+		{
+			name:     "framework bad request",
+			echoErr:  echo.NewHTTPError(http.StatusBadRequest, "bad"),
+			fiberErr: fiber.NewError(http.StatusBadRequest, "bad"),
+			status:   http.StatusBadRequest,
+			body:     `{"code":"E0400","message":"bad"}`,
+		},
+		{
+			name:     "framework server error",
+			echoErr:  echo.NewHTTPError(http.StatusBadGateway, "upstream"),
+			fiberErr: fiber.NewError(http.StatusBadGateway, "upstream"),
+			status:   http.StatusBadGateway,
+			body:     `{"code":"E9999","message":"upstream"}`,
+			logged:   true,
+		},
+		{
+			name:     "response error",
+			echoErr:  NewError(UnAuthorizeCode, "token expired"),
+			fiberErr: NewError(UnAuthorizeCode, "token expired"),
+			status:   http.StatusUnauthorized,
+			body:     `{"code":"E0401","message":"token expired"}`,
+		},
+		{
+			name:     "unknown error",
+			echoErr:  errors.New("pq: connection refused"),
+			fiberErr: errors.New("pq: connection refused"),
+			status:   http.StatusInternalServerError,
+			body:     `{"code":"E9999","message":"Internal server error"}`,
+			logged:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, logs := observedLogger()
+
+			e := echo.New()
+			e.HTTPErrorHandler = EchoErrorHandler(log)
+			e.GET("/", func(echo.Context) error { return tt.echoErr })
+
+			f := fiber.New(fiber.Config{ErrorHandler: FiberErrorHandler(log)})
+			f.Get("/", func(*fiber.Ctx) error { return tt.fiberErr })
+
+			got := map[string]served{
+				"echo":  serveRouter(t, e, http.MethodGet, "/"),
+				"fiber": serveFiberApp(t, f, http.MethodGet, "/"),
+			}
+			for name, res := range got {
+				if res.status != tt.status || res.body != tt.body {
+					t.Errorf("%s = %d %s, want %d %s", name, res.status, res.body, tt.status, tt.body)
+				}
+			}
+
+			wantLogs := 0
+			if tt.logged {
+				wantLogs = len(got)
+			}
+			if n := logs.FilterMessage("request failed").Len(); n != wantLogs {
+				t.Errorf("logged %d request failures, want %d", n, wantLogs)
+			}
+		})
+	}
+}
+
+func TestEchoErrorHandlerHead(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = EchoErrorHandler(nil)
+	e.HEAD("/", func(echo.Context) error { return NewError(NotFoundCode, "") })
+
+	res := serveRouter(t, e, http.MethodHead, "/")
+	if res.status != http.StatusNotFound || res.body != "" {
+		t.Errorf("HEAD = %d %q, want 404 with no body", res.status, res.body)
+	}
+}
+
+func serveRouter(t *testing.T, h http.Handler, method, target string) served {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+	return served{rec.Code, rec.Header(), strings.TrimSuffix(rec.Body.String(), "\n")}
+}
+
+func serveFiberApp(t *testing.T, app *fiber.App, method, target string) served {
+	t.Helper()
+	res, err := app.Test(httptest.NewRequest(method, target, nil), -1)
+	if err != nil {
+		t.Fatalf("fiber app.Test() error = %v", err)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("read fiber body: %v", err)
+	}
+	return served{res.StatusCode, res.Header, string(body)}
+}
@@ -0,0 +1,85 @@
+package response
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gofiber/fiber/v2"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// DefaultPage is used when a page below 1 is requested.
+	DefaultPage = 1
+	// DefaultPageSize is used when a page size of 0 or less is requested.
+	DefaultPageSize = 20
+)
+
+type Pagination struct {
+	Page       int    `json:"page"`
+	PageSize   int    `json:"page_size"`
+	TotalItems int64  `json:"total_items"`
+	TotalPages int    `json:"total_pages"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type Paginated[T any] struct {
+	Items []T `json:"items"`
+	Pagination
+}
+
+// NewPagination builds offset pagination metadata. A page below 1 becomes
+// DefaultPage, a size of 0 or less becomes DefaultPageSize and a negative
+// total becomes 0.
+func NewPagination(page, size int, total int64) Pagination {
+	if page < 1 {
+		page = DefaultPage
+	}
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+	if total < 0 {
+		total = 0
+	}
+
+	return Pagination{
+		Page:       page,
+		PageSize:   size,
+		TotalItems: total,
+		TotalPages: int((total + int64(size) - 1) / int64(size)),
+	}
+}
+
+// NewCursorPagination builds cursor pagination metadata. An empty
+// nextCursor marks the last page.
+func NewCursorPagination(size int, nextCursor string) Pagination {
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+
+	return Pagination{
+		Page:       DefaultPage,
+		PageSize:   size,
+		NextCursor: nextCursor,
+	}
+}
+
+func newPaginated[T any](items []T, pagination Pagination) Paginated[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return Paginated[T]{
+		Items:      items,
+		Pagination: pagination,
+	}
+}
+
+func NewGinPaginatedResponse[T any](c *gin.Context, httpStatusCode int, items []T, pagination Pagination, opts ...MetaOption) {
+	NewGinResponse(c, httpStatusCode, newPaginated(items, pagination), opts...)
+}
+
+func NewEchoPaginatedResponse[T any](c echo.Context, items []T, pagination Pagination, opts ...MetaOption) error {
+	return NewEchoResponse(c, SuccessCode, newPaginated(items, pagination), opts...)
+}
+
+func NewFiberPaginatedResponse[T any](c *fiber.Ctx, items []T, pagination Pagination, opts ...MetaOption) error {
+	return NewFiberResponse(c, SuccessCode, newPaginated(items, pagination), opts...)
+}
@@ -0,0 +1,126 @@
+package response
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofiber/fiber/v2"
+	"github.com/labstack/echo/v4"
+)
+
+func TestNewPagination(t *testing.T) {
+	tests := []struct {
+		name       string
+		page, size int
+		total      int64
+		want       Pagination
+	}{
+		{"exact pages", 2, 10, 30, Pagination{Page: 2, PageSize: 10, TotalItems: 30, TotalPages: 3}},
+		{"partial last page", 1, 10, 31, Pagination{Page: 1, PageSize: 10, TotalItems: 31, TotalPages: 4}},
+		{"no items", 1, 10, 0, Pagination{Page: 1, PageSize: 10, TotalItems: 0, TotalPages: 0}},
+		{"page below 1", 0, 10, 5, Pagination{Page: DefaultPage, PageSize: 10, TotalItems: 5, TotalPages: 1}},
+		{"negative page", -3, 10, 5, Pagination{Page: DefaultPage, PageSize: 10, TotalItems: 5, TotalPages: 1}},
+		{"zero size", 1, 0, 45, Pagination{Page: 1, PageSize: DefaultPageSize, TotalItems: 45, TotalPages: 3}},
+		{"negative size", 1, -1, 45, Pagination{Page: 1, PageSize: DefaultPageSize, TotalItems: 45, TotalPages: 3}},
+		{"negative total", 1, 10, -7, Pagination{Page: 1, PageSize: 10, TotalItems: 0, TotalPages: 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewPagination(tt.page, tt.size, tt.total); got != tt.want {
+				t.Errorf("NewPagination(%d, %d, %d) = %+v, want %+v", tt.page, tt.size, tt.total, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCursorPagination(t *testing.T) {
+	want := Pagination{Page: DefaultPage, PageSize: DefaultPageSize, NextCursor: "abc"}
+	if got := NewCursorPagination(0, "abc"); got != want {
+		t.Errorf("NewCursorPagination() = %+v, want %+v", got, want)
+	}
+}
+
+type paginatedItem struct {
+	ID int `json:"id"`
+}
+
+func TestPaginatedResponseJSON(t *testing.T) {
+	const (
+		offsetJSON = `{"code":"00000","message":"","data":{"items":[{"id":1},{"id":2}],"page":2,"page_size":2,"total_items":5,"total_pages":3}}`
+		cursorJSON = `{"code":"00000","message":"","data":{"items":[],"page":1,"page_size":2,"total_items":0,"total_pages":0,"next_cursor":"c2"}}`
+	)
+	items := []paginatedItem{{ID: 1}, {ID: 2}}
+
+	tests := []struct {
+		name       string
+		items      []paginatedItem
+		pagination Pagination
+		want       string
+	}{
+		{"offset", items, NewPagination(2, 2, 5), offsetJSON},
+		{"cursor with nil items", nil, NewCursorPagination(2, "c2"), cursorJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+"/gin", func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			NewGinPaginatedResponse(c, http.StatusOK, tt.items, tt.pagination)
+			assertJSON(t, rec.Body.Bytes(), tt.want)
+		})
+
+		t.Run(tt.name+"/echo", func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+			if err := NewEchoPaginatedResponse(c, tt.items, tt.pagination); err != nil {
+				t.Fatal(err)
+			}
+			assertJSON(t, rec.Body.Bytes(), tt.want)
+		})
+
+		t.Run(tt.name+"/fiber", func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error {
+				return NewFiberPaginatedResponse(c, tt.items, tt.pagination)
+			})
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertJSON(t, body, tt.want)
+		})
+	}
+}
+
+// assertJSON compares got and want as compact JSON so that formatting
+// differences between frameworks do not matter.
+func assertJSON(t *testing.T, got []byte, want string) {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal(got, &v); err != nil {
+		t.Fatalf("invalid JSON %q: %v", got, err)
+	}
+	compact, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var w any
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatal(err)
+	}
+	wantCompact, _ := json.Marshal(w)
+	if string(compact) != string(wantCompact) {
+		t.Errorf("JSON = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,116 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const contentTypeProblemJSON = "application/problem+json"
+
+// Problem is an RFC 7807 problem details object. Extensions are serialized
+// as additional top-level members; they cannot replace the standard ones.
+type Problem struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]interface{}
+}
+
+func (p Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]interface{}, len(p.Extensions)+5)
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+
+	problemType := p.Type
+	if problemType == "" {
+		problemType = "about:blank"
+	}
+	members["type"] = problemType
+	if p.Title != "" {
+		members["title"] = p.Title
+	}
+	if p.Status != 0 {
+		members["status"] = p.Status
+	}
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+
+	return json.Marshal(members)
+}
+
+// FromResponseError converts err into a Problem. The title is the default
+// message registered for the error's code, the detail its message, and the
+// code is kept in a "code" extension member. A status of 0 uses the status
+// registered for the code.
+func FromResponseError(err error, status int) Problem {
+	errRes := toResponseError(err)
+	if status <= 0 {
+		status = HTTPStatus(errRes.Code)
+	}
+
+	return Problem{
+		Title:  DefaultMessage(errRes.Code),
+		Status: status,
+		Detail: errRes.Message,
+		Extensions: map[string]interface{}{
+			"code": errRes.Code,
+		},
+	}
+}
+
+// NewGinProblem responds with p as application/problem+json.
+func NewGinProblem(c *gin.Context, status int, p Problem) {
+	if p.Status == 0 {
+		p.Status = status
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		c.Data(http.StatusInternalServerError, contentTypeJSON, fallbackErrorBody)
+		return
+	}
+	c.Data(status, contentTypeProblemJSON, data)
+}
+
+// WriteProblem writes p as application/problem+json to a plain net/http
+// response.
+func WriteProblem(w http.ResponseWriter, status int, p Problem) error {
+	if p.Status == 0 {
+		p.Status = status
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		w.Header().Set("Content-Type", contentTypeJSON)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(fallbackErrorBody)
+		return err
+	}
+
+	w.Header().Set("Content-Type", contentTypeProblemJSON)
+	w.WriteHeader(status)
+	_, err = w.Write(data)
+	return err
+}
+
+// GinProblemMiddleware renders the last error added with c.Error as a
+// problem when the handler has not written a response, so handlers that
+// report *ResponseError values need no changes.
+func GinProblemMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		p := FromResponseError(c.Errors.Last().Err, 0)
+		NewGinProblem(c, p.Status, p)
+	}
+}
@@ -0,0 +1,106 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestProblemMarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		problem Problem
+		want    string
+	}{
+		{
+			name:    "empty defaults to about:blank",
+			problem: Problem{},
+			want:    `{"type":"about:blank"}`,
+		},
+		{
+			name: "all members",
+			problem: Problem{
+				Type:     "https://example.com/probs/out-of-credit",
+				Title:    "You do not have enough credit.",
+				Status:   http.StatusForbidden,
+				Detail:   "Your current balance is 30, but that costs 50.",
+				Instance: "/account/12345/msgs/abc",
+			},
+			want: `{"detail":"Your current balance is 30, but that costs 50.","instance":"/account/12345/msgs/abc","status":403,"title":"You do not have enough credit.","type":"https://example.com/probs/out-of-credit"}`,
+		},
+		{
+			name: "extensions",
+			problem: Problem{
+				Title:      "Bad request",
+				Status:     http.StatusBadRequest,
+				Extensions: map[string]interface{}{"code": "E0400", "invalid_params": []string{"age"}},
+			},
+			want: `{"code":"E0400","invalid_params":["age"],"status":400,"title":"Bad request","type":"about:blank"}`,
+		},
+		{
+			name: "extensions cannot replace standard members",
+			problem: Problem{
+				Type:       "urn:problem:conflict",
+				Status:     http.StatusConflict,
+				Extensions: map[string]interface{}{"type": "spoofed", "status": 200, "title": "spoofed"},
+			},
+			want: `{"status":409,"title":"spoofed","type":"urn:problem:conflict"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.problem)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromResponseError(t *testing.T) {
+	p := FromResponseError(NewError(NotFoundCode, "order not found"), 0)
+	got, _ := json.Marshal(p)
+	if want := `{"code":"E0404","detail":"order not found","status":404,"title":"Not found","type":"about:blank"}`; string(got) != want {
+		t.Errorf("FromResponseError() = %s, want %s", got, want)
+	}
+
+	p = FromResponseError(errors.New("secret"), http.StatusBadGateway)
+	got, _ = json.Marshal(p)
+	if want := `{"code":"E9999","detail":"Internal server error","status":502,"title":"Internal server error","type":"about:blank"}`; string(got) != want {
+		t.Errorf("FromResponseError() of an unknown error = %s, want %s", got, want)
+	}
+}
+
+func TestGinProblemMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(GinProblemMiddleware())
+	r.GET("/fail", func(c *gin.Context) { c.Error(NewError(BadRequestCode, "bad id")) })
+	r.GET("/written", func(c *gin.Context) {
+		c.Error(NewError(BadRequestCode, "ignored"))
+		c.String(http.StatusOK, "ok")
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fail", nil))
+	if rec.Code != http.StatusBadRequest || rec.Header().Get("Content-Type") != contentTypeProblemJSON {
+		t.Errorf("/fail = %d %q, want 400 %q", rec.Code, rec.Header().Get("Content-Type"), contentTypeProblemJSON)
+	}
+	if want := `{"code":"E0400","detail":"bad id","status":400,"title":"Bad request","type":"about:blank"}`; rec.Body.String() != want {
+		t.Errorf("/fail body = %s, want %s", rec.Body, want)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/written", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "ok" {
+		t.Errorf("/written = %d %s, want the handler's own response", rec.Code, rec.Body)
+	}
+}
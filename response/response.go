@@ -1,6 +1,7 @@
 package response
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -10,78 +11,178 @@ import (
 )
 
 type response struct {
-	Code    ResponseCode `json:"code"`
-	Message string       `json:"message"`
-	Data    interface{}  `json:"data"`
+	Code    ResponseCode           `json:"code"`
+	Message string                 `json:"message"`
+	Data    interface{}            `json:"data"`
+	Meta    map[string]interface{} `json:"meta,omitempty"`
+}
+
+// MetaOption adds auxiliary information to the "meta" member of a success
+// response.
+type MetaOption func(meta map[string]interface{})
+
+// WithMeta sets meta[key] to value. Options apply in order, so a later
+// option overrides an earlier one with the same key.
+func WithMeta(key string, value interface{}) MetaOption {
+	return func(meta map[string]interface{}) {
+		meta[key] = value
+	}
 }
 
-type responseError struct {
+// ResponseError is the error envelope rendered by the error helpers. The
+// optional cause is kept for errors.Is/As and logging and is never sent to
+// clients.
+type ResponseError struct {
 	Code    ResponseCode `json:"code"`
 	Message string       `json:"message"`
+	cause   error
 }
 
-func (r *responseError) Error() string {
+func (r *ResponseError) Error() string {
+	if r.cause != nil {
+		return fmt.Sprintf("status %v: err %v: %v", r.Code, r.Message, r.cause)
+	}
 	return fmt.Sprintf("status %v: err %v", r.Code, r.Message)
 }
 
+// Unwrap returns the cause passed to NewErrorWrap.
+func (r *ResponseError) Unwrap() error {
+	return r.cause
+}
+
 func NewError(code ResponseCode, err string) error {
-	return &responseError{
+	return &ResponseError{
 		Code:    code,
 		Message: err,
 	}
 }
 
-func NewGinResponse(c *gin.Context, httpStatusCode int, data interface{}) {
-	c.JSON(httpStatusCode, response{
-		Code:    "00000",
-		Message: "",
-		Data:    data,
-	})
+// NewErrorWrap is NewError with an underlying cause that stays reachable
+// through errors.Is and errors.As.
+func NewErrorWrap(code ResponseCode, msg string, cause error) error {
+	return &ResponseError{
+		Code:    code,
+		Message: msg,
+		cause:   cause,
+	}
 }
 
-func NewGinResponseError(c *gin.Context, httpStatusCode int, err error) {
-	c.JSON(httpStatusCode, err)
+// CodeOf returns the code of the first *ResponseError in err's chain,
+// SuccessCode for a nil error and GenericError otherwise.
+func CodeOf(err error) ResponseCode {
+	if err == nil {
+		return SuccessCode
+	}
+	var errRes *ResponseError
+	if errors.As(err, &errRes) {
+		return errRes.Code
+	}
+	return GenericError
 }
 
-func NewEchoResponse(c echo.Context, statusCode ResponseCode, data interface{}) error {
-	return c.JSON(http.StatusOK, response{
-		Code:    "00000",
-		Message: "",
-		Data:    data,
-	})
+// IsCode reports whether err carries code.
+func IsCode(err error, code ResponseCode) bool {
+	return err != nil && CodeOf(err) == code
 }
 
-func NewEchoResponseError(c echo.Context, httpStatusCode int, err error) error {
-	errRes := &responseError{
-		Code:    GenericError,
-		Message: "Internal server error",
+// toResponseError finds a *ResponseError in err's chain. Any other error is
+// reported as GenericError with the generic message, as frameworkError does,
+// so internal error text never reaches the client.
+func toResponseError(err error) *ResponseError {
+	var errRes *ResponseError
+	if errors.As(err, &errRes) {
+		return errRes
 	}
+	return newCodeError(GenericError, "")
+}
 
-	if err, ok := err.(*responseError); ok {
-		errRes = err
+// newSuccess builds the success envelope. An empty code becomes SuccessCode
+// and meta is left nil unless an option sets a key.
+func newSuccess(code ResponseCode, message string, data interface{}, opts []MetaOption) response {
+	if code == "" {
+		code = SuccessCode
 	}
+	res := response{
+		Code:    code,
+		Message: message,
+		Data:    data,
+	}
+	if len(opts) > 0 {
+		meta := make(map[string]interface{})
+		for _, opt := range opts {
+			opt(meta)
+		}
+		if len(meta) > 0 {
+			res.Meta = meta
+		}
+	}
+	return res
+}
 
-	return c.JSON(httpStatusCode, errRes)
+func NewGinResponse(c *gin.Context, httpStatusCode int, data interface{}, opts ...MetaOption) {
+	NewGinResponseWithCode(c, httpStatusCode, SuccessCode, "", data, opts...)
 }
 
-func NewFiberResponse(c *fiber.Ctx, statusCode ResponseCode, data interface{}) error {
-	return c.Status(http.StatusOK).JSON(response{
-		Code:    "00000",
-		Message: "",
-		Data:    data,
-	})
+// NewGinResponseWithCode responds with a custom success code and message.
+func NewGinResponseWithCode(c *gin.Context, httpStatusCode int, code ResponseCode, message string, data interface{}, opts ...MetaOption) {
+	c.JSON(httpStatusCode, newSuccess(code, message, data, opts))
+}
+
+func NewGinResponseError(c *gin.Context, httpStatusCode int, err error) {
+	c.JSON(httpStatusCode, localizeError(toResponseError(err), ginLanguages(c)))
+}
+
+// NewEchoResponse responds with 200 and statusCode as the envelope code.
+func NewEchoResponse(c echo.Context, statusCode ResponseCode, data interface{}, opts ...MetaOption) error {
+	return NewEchoResponseWithCode(c, statusCode, "", data, opts...)
+}
+
+// NewEchoResponseWithCode responds with a custom success code and message.
+func NewEchoResponseWithCode(c echo.Context, code ResponseCode, message string, data interface{}, opts ...MetaOption) error {
+	return c.JSON(http.StatusOK, newSuccess(code, message, data, opts))
+}
+
+func NewEchoResponseError(c echo.Context, httpStatusCode int, err error) error {
+	return c.JSON(httpStatusCode, localizeError(toResponseError(err), echoLanguages(c)))
+}
+
+// NewFiberResponse responds with 200 and statusCode as the envelope code.
+func NewFiberResponse(c *fiber.Ctx, statusCode ResponseCode, data interface{}, opts ...MetaOption) error {
+	return NewFiberResponseWithCode(c, statusCode, "", data, opts...)
+}
+
+// NewFiberResponseWithCode responds with a custom success code and message.
+func NewFiberResponseWithCode(c *fiber.Ctx, code ResponseCode, message string, data interface{}, opts ...MetaOption) error {
+	return c.Status(http.StatusOK).JSON(newSuccess(code, message, data, opts))
 }
 
 func NewFiberResponseError(c *fiber.Ctx, httpStatusCode int, err error) error {
+	return c.Status(httpStatusCode).JSON(localizeError(toResponseError(err), fiberLanguages(c)))
+}
 
-	errRes := &responseError{
-		Code:    GenericError,
-		Message: "Internal server error",
+// newCodeError builds the error body for code, using the registered default
+// message when message is empty.
+func newCodeError(code ResponseCode, message string) *ResponseError {
+	if message == "" {
+		message = DefaultMessage(code)
 	}
-
-	if err, ok := err.(*responseError); ok {
-		errRes = err
+	return &ResponseError{
+		Code:    code,
+		Message: message,
 	}
+}
+
+// NewGinError responds with code and the HTTP status registered for it.
+func NewGinError(c *gin.Context, code ResponseCode, message string) {
+	c.JSON(HTTPStatus(code), localizeError(newCodeError(code, message), ginLanguages(c)))
+}
+
+// NewEchoError responds with code and the HTTP status registered for it.
+func NewEchoError(c echo.Context, code ResponseCode, message string) error {
+	return c.JSON(HTTPStatus(code), localizeError(newCodeError(code, message), echoLanguages(c)))
+}
 
-	return c.Status(httpStatusCode).JSON(errRes)
+// NewFiberError responds with code and the HTTP status registered for it.
+func NewFiberError(c *fiber.Ctx, code ResponseCode, message string) error {
+	return c.Status(HTTPStatus(code)).JSON(localizeError(newCodeError(code, message), fiberLanguages(c)))
 }
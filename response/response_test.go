@@ -0,0 +1,220 @@
+package response
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofiber/fiber/v2"
+	"github.com/labstack/echo/v4"
+)
+
+// served is the status, headers and body written by one framework.
+type served struct {
+	status int
+	header http.Header
+	body   string
+}
+
+func serveGin(t *testing.T, req *http.Request, h gin.HandlerFunc) served {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = req
+	h(c)
+	// gin.Engine flushes the status after the handlers run; do the same.
+	c.Writer.WriteHeaderNow()
+	return served{rec.Code, rec.Header(), rec.Body.String()}
+}
+
+func serveEcho(t *testing.T, req *http.Request, h echo.HandlerFunc) served {
+	t.Helper()
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	if err := h(e.NewContext(req, rec)); err != nil {
+		e.HTTPErrorHandler(err, e.NewContext(req, rec))
+	}
+	// echo's JSON encoder ends the body with a newline; gin and fiber do not.
+	return served{rec.Code, rec.Header(), strings.TrimSuffix(rec.Body.String(), "\n")}
+}
+
+func serveFiber(t *testing.T, req *http.Request, h fiber.Handler) served {
+	t.Helper()
+	app := fiber.New()
+	app.All("/*", h)
+	return serveFiberApp(t, app, req.Method, req.URL.RequestURI())
+}
+
+// serveAll runs the same response through gin, echo and fiber. Each
+// framework gets its own copy of a GET / request.
+func serveAll(t *testing.T, ginH gin.HandlerFunc, echoH echo.HandlerFunc, fiberH fiber.Handler) map[string]served {
+	t.Helper()
+	return map[string]served{
+		"gin":   serveGin(t, httptest.NewRequest(http.MethodGet, "/", nil), ginH),
+		"echo":  serveEcho(t, httptest.NewRequest(http.MethodGet, "/", nil), echoH),
+		"fiber": serveFiber(t, httptest.NewRequest(http.MethodGet, "/", nil), fiberH),
+	}
+}
+
+func TestResponseErrorSymmetry(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"response error", NewError(BadRequestCode, "invalid id"), `{"code":"E0400","message":"invalid id"}`},
+		{"wrapped response error", fmt.Errorf("load order: %w", NewError(NotFoundCode, "order not found")), `{"code":"E0404","message":"order not found"}`},
+		{"unknown error", errors.New("pq: password authentication failed"), `{"code":"E9999","message":"Internal server error"}`},
+		{"wrapped unknown error", fmt.Errorf("load order: %w", io.ErrUnexpectedEOF), `{"code":"E9999","message":"Internal server error"}`},
+		{"nil error", nil, `{"code":"E9999","message":"Internal server error"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := serveAll(t,
+				func(c *gin.Context) { NewGinResponseError(c, http.StatusTeapot, tt.err) },
+				func(c echo.Context) error { return NewEchoResponseError(c, http.StatusTeapot, tt.err) },
+				func(c *fiber.Ctx) error { return NewFiberResponseError(c, http.StatusTeapot, tt.err) },
+			)
+			for name, res := range got {
+				if res.status != http.StatusTeapot {
+					t.Errorf("%s status = %d, want %d", name, res.status, http.StatusTeapot)
+				}
+				if res.body != tt.want {
+					t.Errorf("%s body = %s, want %s", name, res.body, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestResponseWithCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    ResponseCode
+		message string
+		data    interface{}
+		want    string
+	}{
+		{"custom code and message", "S0201", "Created", map[string]int{"id": 7}, `{"code":"S0201","message":"Created","data":{"id":7}}`},
+		{"empty code is success", "", "", []string{"a"}, `{"code":"00000","message":"","data":["a"]}`},
+		{"nil data", SuccessCode, "ok", nil, `{"code":"00000","message":"ok","data":null}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := serveAll(t,
+				func(c *gin.Context) { NewGinResponseWithCode(c, http.StatusOK, tt.code, tt.message, tt.data) },
+				func(c echo.Context) error { return NewEchoResponseWithCode(c, tt.code, tt.message, tt.data) },
+				func(c *fiber.Ctx) error { return NewFiberResponseWithCode(c, tt.code, tt.message, tt.data) },
+			)
+			for name, res := range got {
+				if res.status != http.StatusOK {
+					t.Errorf("%s status = %d, want 200", name, res.status)
+				}
+				if res.body != tt.want {
+					t.Errorf("%s body = %s, want %s", name, res.body, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestResponseShorthands(t *testing.T) {
+	got := serveAll(t,
+		func(c *gin.Context) { NewGinResponse(c, http.StatusOK, "x") },
+		func(c echo.Context) error { return NewEchoResponse(c, SuccessCode, "x") },
+		func(c *fiber.Ctx) error { return NewFiberResponse(c, SuccessCode, "x") },
+	)
+	for name, res := range got {
+		if want := `{"code":"00000","message":"","data":"x"}`; res.status != http.StatusOK || res.body != want {
+			t.Errorf("%s = %d %s, want 200 %s", name, res.status, res.body, want)
+		}
+	}
+}
+
+type queryError struct{ table string }
+
+func (e *queryError) Error() string { return "query " + e.table + " failed" }
+
+func TestNewErrorWrap(t *testing.T) {
+	cause := &queryError{table: "orders"}
+	wrapped := NewErrorWrap(NotFoundCode, "order not found", fmt.Errorf("repository: %w", cause))
+	err := fmt.Errorf("handler: %w", wrapped)
+
+	var gotCause *queryError
+	if !errors.As(err, &gotCause) || gotCause != cause {
+		t.Errorf("errors.As(err, *queryError) = %v, want the original cause", gotCause)
+	}
+	if !errors.Is(err, cause) {
+		t.Error("errors.Is(err, cause) = false, want true")
+	}
+	var errRes *ResponseError
+	if !errors.As(err, &errRes) || errRes.Code != NotFoundCode {
+		t.Errorf("errors.As(err, *ResponseError) = %v, want code %s", errRes, NotFoundCode)
+	}
+	if got := CodeOf(err); got != NotFoundCode {
+		t.Errorf("CodeOf() = %s, want %s", got, NotFoundCode)
+	}
+	if !IsCode(err, NotFoundCode) || IsCode(err, BadRequestCode) {
+		t.Error("IsCode() did not match only NotFoundCode")
+	}
+	if want := "status E0404: err order not found: repository: query orders failed"; wrapped.Error() != want {
+		t.Errorf("Error() = %q, want %q", wrapped.Error(), want)
+	}
+	if errors.Unwrap(NewError(NotFoundCode, "x")) != nil {
+		t.Error("NewError() has a cause")
+	}
+
+	// The cause never reaches the client.
+	res := serveGin(t, httptest.NewRequest(http.MethodGet, "/", nil), func(c *gin.Context) { NewGinResponseError(c, http.StatusNotFound, err) })
+	if want := `{"code":"E0404","message":"order not found"}`; res.body != want {
+		t.Errorf("body = %s, want %s", res.body, want)
+	}
+}
+
+func TestCodeOf(t *testing.T) {
+	if got := CodeOf(nil); got != SuccessCode {
+		t.Errorf("CodeOf(nil) = %s, want %s", got, SuccessCode)
+	}
+	if got := CodeOf(errors.New("boom")); got != GenericError {
+		t.Errorf("CodeOf(plain error) = %s, want %s", got, GenericError)
+	}
+	if IsCode(nil, SuccessCode) {
+		t.Error("IsCode(nil, SuccessCode) = true, want false")
+	}
+}
+
+func TestWithMeta(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []MetaOption
+		want string
+	}{
+		{"no options", nil, `{"code":"00000","message":"","data":1}`},
+		{"options that set nothing", []MetaOption{func(map[string]interface{}) {}}, `{"code":"00000","message":"","data":1}`},
+		{"one key", []MetaOption{WithMeta("request_id", "r1")}, `{"code":"00000","message":"","data":1,"meta":{"request_id":"r1"}}`},
+		{"later option wins", []MetaOption{WithMeta("page", 1), WithMeta("total", 9), WithMeta("page", 2)}, `{"code":"00000","message":"","data":1,"meta":{"page":2,"total":9}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := serveAll(t,
+				func(c *gin.Context) { NewGinResponse(c, http.StatusOK, 1, tt.opts...) },
+				func(c echo.Context) error { return NewEchoResponse(c, SuccessCode, 1, tt.opts...) },
+				func(c *fiber.Ctx) error { return NewFiberResponse(c, SuccessCode, 1, tt.opts...) },
+			)
+			for name, res := range got {
+				if res.body != tt.want {
+					t.Errorf("%s body = %s, want %s", name, res.body, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package response
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofiber/fiber/v2"
+	"github.com/labstack/echo/v4"
+)
+
+// successStatus returns status when it is a 2xx or 3xx code and 200
+// otherwise.
+func successStatus(status int) int {
+	if status < http.StatusOK || status >= http.StatusBadRequest {
+		return http.StatusOK
+	}
+	return status
+}
+
+// NewGinResponseCreated responds 201 with the success envelope.
+func NewGinResponseCreated(c *gin.Context, data interface{}, opts ...MetaOption) {
+	NewGinResponse(c, http.StatusCreated, data, opts...)
+}
+
+// NewEchoResponseWithStatus responds with the success envelope and status,
+// which must be 2xx or 3xx and otherwise becomes 200.
+func NewEchoResponseWithStatus[T any](c echo.Context, status int, data T, opts ...MetaOption) error {
+	return c.JSON(successStatus(status), newSuccess(SuccessCode, "", data, opts))
+}
+
+// NewFiberResponseWithStatus responds with the success envelope and status,
+// which must be 2xx or 3xx and otherwise becomes 200.
+func NewFiberResponseWithStatus[T any](c *fiber.Ctx, status int, data T, opts ...MetaOption) error {
+	return c.Status(successStatus(status)).JSON(newSuccess(SuccessCode, "", data, opts))
+}
+
+// NewGinNoContent responds 204 with an empty body.
+func NewGinNoContent(c *gin.Context) {
+	c.Status(http.StatusNoContent)
+}
+
+// NewEchoNoContent responds 204 with an empty body.
+func NewEchoNoContent(c echo.Context) error {
+	return c.NoContent(http.StatusNoContent)
+}
+
+// NewFiberNoContent responds 204 with an empty body.
+func NewFiberNoContent(c *fiber.Ctx) error {
+	return c.Status(http.StatusNoContent).Send(nil)
+}
@@ -0,0 +1,77 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofiber/fiber/v2"
+	"github.com/labstack/echo/v4"
+)
+
+func TestResponseWithStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   int
+	}{
+		{http.StatusOK, http.StatusOK},
+		{http.StatusCreated, http.StatusCreated},
+		{http.StatusAccepted, http.StatusAccepted},
+		{http.StatusNotModified, http.StatusNotModified},
+		{http.StatusBadRequest, http.StatusOK},
+		{http.StatusInternalServerError, http.StatusOK},
+		{0, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			got := map[string]served{
+				"echo": serveEcho(t, httptest.NewRequest(http.MethodGet, "/", nil), func(c echo.Context) error {
+					return NewEchoResponseWithStatus(c, tt.status, "x")
+				}),
+				"fiber": serveFiber(t, httptest.NewRequest(http.MethodGet, "/", nil), func(c *fiber.Ctx) error {
+					return NewFiberResponseWithStatus(c, tt.status, "x")
+				}),
+			}
+			for name, res := range got {
+				if res.status != tt.want {
+					t.Errorf("%s status = %d, want %d", name, res.status, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestResponseCreated(t *testing.T) {
+	got := serveAll(t,
+		func(c *gin.Context) { NewGinResponseCreated(c, map[string]int{"id": 1}) },
+		func(c echo.Context) error {
+			return NewEchoResponseWithStatus(c, http.StatusCreated, map[string]int{"id": 1})
+		},
+		func(c *fiber.Ctx) error {
+			return NewFiberResponseWithStatus(c, http.StatusCreated, map[string]int{"id": 1})
+		},
+	)
+	for name, res := range got {
+		if want := `{"code":"00000","message":"","data":{"id":1}}`; res.status != http.StatusCreated || res.body != want {
+			t.Errorf("%s = %d %s, want 201 %s", name, res.status, res.body, want)
+		}
+	}
+}
+
+func TestResponseNoContent(t *testing.T) {
+	got := serveAll(t,
+		func(c *gin.Context) { NewGinNoContent(c) },
+		func(c echo.Context) error { return NewEchoNoContent(c) },
+		func(c *fiber.Ctx) error { return NewFiberNoContent(c) },
+	)
+	for name, res := range got {
+		if res.status != http.StatusNoContent {
+			t.Errorf("%s status = %d, want 204", name, res.status)
+		}
+		if res.body != "" {
+			t.Errorf("%s body = %q, want empty", name, res.body)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// Package shutdown runs cleanup hooks in a fixed order when the process is
+// asked to stop.
+//
+//	sd := shutdown.New()
+//	sd.Register("http", shutdown.PriorityHTTP, srv.Shutdown)
+//	sd.Register("orders-consumer", shutdown.PriorityConsumers, consumer.Close)
+//	sd.Register("postgres", shutdown.PriorityStorage, manager.Close)
+//	if err := sd.Wait(context.Background()); err != nil {
+//		log.Error("shutdown finished with errors", zap.Error(err))
+//	}
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/11SF/go-common/logger"
+	"go.uber.org/zap"
+)
+
+// Suggested priorities. Lower values run first; hooks sharing a priority
+// run concurrently.
+const (
+	PriorityHTTP      = 0
+	PriorityConsumers = 10
+	PriorityStorage   = 20
+	PriorityTelemetry = 30
+)
+
+// DefaultHookTimeout bounds each hook when WithHookTimeout is not given.
+const DefaultHookTimeout = 10 * time.Second
+
+type hook struct {
+	name     string
+	priority int
+	fn       func(ctx context.Context) error
+}
+
+// Coordinator collects hooks and runs them once on shutdown.
+type Coordinator struct {
+	log         *zap.Logger
+	hookTimeout time.Duration
+	signals     []os.Signal
+	exit        func(code int)
+
+	mu    sync.Mutex
+	hooks []hook
+
+	once sync.Once
+	done chan struct{}
+	err  error
+}
+
+type Option func(*Coordinator)
+
+// WithLogger sets the logger for hook results. The default logs at info
+// level.
+func WithLogger(log *zap.Logger) Option {
+	return func(c *Coordinator) {
+		c.log = log
+	}
+}
+
+// WithHookTimeout bounds each hook. Values of zero or less, like the
+// default, use DefaultHookTimeout.
+func WithHookTimeout(d time.Duration) Option {
+	return func(c *Coordinator) {
+		c.hookTimeout = d
+	}
+}
+
+// WithSignals replaces the signals Wait listens for, SIGINT and SIGTERM by
+// default.
+func WithSignals(signals ...os.Signal) Option {
+	return func(c *Coordinator) {
+		c.signals = signals
+	}
+}
+
+func New(opts ...Option) *Coordinator {
+	c := &Coordinator{
+		hookTimeout: DefaultHookTimeout,
+		signals:     []os.Signal{syscall.SIGINT, syscall.SIGTERM},
+		exit:        os.Exit,
+		done:        make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.hookTimeout <= 0 {
+		c.hookTimeout = DefaultHookTimeout
+	}
+	if c.log == nil {
+		c.log = logger.CreateLogger(logger.Config{})
+	}
+	return c
+}
+
+// Register adds fn to run at priority. Close(ctx) methods such as
+// http.Server.Shutdown or the kafka and database wrappers fit directly.
+func (c *Coordinator) Register(name string, priority int, fn func(ctx context.Context) error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hooks = append(c.hooks, hook{name: name, priority: priority, fn: fn})
+}
+
+// Wait blocks until a shutdown signal arrives or ctx is done, then runs the
+// hooks. A second signal while hooks are running exits the process with
+// status 1. Every call returns the result of the single shutdown run.
+func (c *Coordinator) Wait(ctx context.Context) error {
+	sig := make(chan os.Signal, 2)
+	signal.Notify(sig, c.signals...)
+	defer signal.Stop(sig)
+
+	select {
+	case s := <-sig:
+		c.log.Info("shutdown signal received", zap.String("signal", s.String()))
+	case <-ctx.Done():
+	case <-c.done:
+		return c.err
+	}
+
+	go func() {
+		select {
+		case s := <-sig:
+			c.log.Error("second shutdown signal received, forcing exit", zap.String("signal", s.String()))
+			c.exit(1)
+		case <-c.done:
+		}
+	}()
+
+	return c.Shutdown(context.WithoutCancel(ctx))
+}
+
+// Shutdown runs the hooks by ascending priority and returns their joined
+// errors. Only the first call runs them; later calls wait for it and return
+// the same result.
+func (c *Coordinator) Shutdown(ctx context.Context) error {
+	c.once.Do(func() {
+		c.err = c.run(ctx)
+		close(c.done)
+	})
+	<-c.done
+	return c.err
+}
+
+func (c *Coordinator) run(ctx context.Context) error {
+	c.mu.Lock()
+	hooks := append([]hook(nil), c.hooks...)
+	c.mu.Unlock()
+	sort.SliceStable(hooks, func(i, j int) bool {
+		return hooks[i].priority < hooks[j].priority
+	})
+
+	var errs []error
+	for start := 0; start < len(hooks); {
+		end := start
+		for end < len(hooks) && hooks[end].priority == hooks[start].priority {
+			end++
+		}
+		errs = append(errs, c.runGroup(ctx, hooks[start:end])...)
+		start = end
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Coordinator) runGroup(ctx context.Context, hooks []hook) []error {
+	errs := make([]error, len(hooks))
+	var wg sync.WaitGroup
+	for i, h := range hooks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = c.runHook(ctx, h)
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+// runHook returns once fn does or the hook timeout passes, whichever is
+// first; a hook that ignores its ctx is left running.
+func (c *Coordinator) runHook(ctx context.Context, h hook) error {
+	ctx, cancel := context.WithTimeout(ctx, c.hookTimeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- h.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	fields := []zap.Field{
+		zap.String("hook", h.name),
+		zap.Int("priority", h.priority),
+		zap.Duration("duration", time.Since(start)),
+	}
+	if err != nil {
+		c.log.Error("shutdown hook failed", append(fields, zap.Error(err))...)
+		return fmt.Errorf("shutdown: %s: %w", h.name, err)
+	}
+	c.log.Info("shutdown hook finished", fields...)
+	return nil
+}
@@ -0,0 +1,242 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func newTestCoordinator(opts ...Option) *Coordinator {
+	return New(append([]Option{WithLogger(zap.NewNop())}, opts...)...)
+}
+
+// eventLog records hook events in order.
+type eventLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *eventLog) add(event string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+}
+
+func (l *eventLog) index(event string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, e := range l.events {
+		if e == event {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestShutdownOrder(t *testing.T) {
+	c := newTestCoordinator()
+	var log eventLog
+
+	// Both PriorityHTTP hooks wait for each other, so the group only
+	// finishes if they run concurrently.
+	var started sync.WaitGroup
+	started.Add(2)
+	for _, name := range []string{"http-a", "http-b"} {
+		c.Register(name, PriorityHTTP, func(context.Context) error {
+			log.add(name + " start")
+			started.Done()
+			started.Wait()
+			time.Sleep(10 * time.Millisecond)
+			log.add(name + " end")
+			return nil
+		})
+	}
+	for _, h := range []struct {
+		name     string
+		priority int
+	}{{"telemetry", PriorityTelemetry}, {"storage", PriorityStorage}, {"consumers", PriorityConsumers}} {
+		c.Register(h.name, h.priority, func(context.Context) error {
+			log.add(h.name + " start")
+			return nil
+		})
+	}
+
+	if err := c.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	for _, before := range []string{"http-a end", "http-b end"} {
+		if log.index(before) > log.index("consumers start") {
+			t.Errorf("%q ran after consumers started: %v", before, log.events)
+		}
+	}
+	order := []string{"consumers start", "storage start", "telemetry start"}
+	for i := 1; i < len(order); i++ {
+		if log.index(order[i-1]) > log.index(order[i]) {
+			t.Errorf("%q ran after %q: %v", order[i-1], order[i], log.events)
+		}
+	}
+}
+
+func TestShutdownHookTimeout(t *testing.T) {
+	c := newTestCoordinator(WithHookTimeout(20 * time.Millisecond))
+	release := make(chan struct{})
+	defer close(release)
+
+	c.Register("stuck", PriorityHTTP, func(context.Context) error {
+		<-release // ignores ctx
+		return nil
+	})
+	var storageRan bool
+	c.Register("storage", PriorityStorage, func(context.Context) error {
+		storageRan = true
+		return nil
+	})
+
+	start := time.Now()
+	err := c.Shutdown(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown() error = %v, want deadline exceeded", err)
+	}
+	if err.Error() != "shutdown: stuck: context deadline exceeded" {
+		t.Errorf("Shutdown() error = %q", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Shutdown() took %s", elapsed)
+	}
+	if !storageRan {
+		t.Error("later priority did not run after the timed-out hook")
+	}
+}
+
+func TestShutdownJoinsErrors(t *testing.T) {
+	c := newTestCoordinator()
+	errA, errB := errors.New("a failed"), errors.New("b failed")
+	c.Register("a", PriorityHTTP, func(context.Context) error { return errA })
+	c.Register("b", PriorityStorage, func(context.Context) error { return errB })
+	c.Register("c", PriorityStorage, func(context.Context) error { return nil })
+
+	err := c.Shutdown(context.Background())
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Fatalf("Shutdown() error = %v, want both hook errors", err)
+	}
+}
+
+func TestWithHookTimeoutNonPositive(t *testing.T) {
+	for _, d := range []time.Duration{0, -time.Second} {
+		if got := newTestCoordinator(WithHookTimeout(d)).hookTimeout; got != DefaultHookTimeout {
+			t.Errorf("WithHookTimeout(%s) = %s, want %s", d, got, DefaultHookTimeout)
+		}
+	}
+
+	c := newTestCoordinator(WithHookTimeout(0))
+	c.Register("quick", PriorityHTTP, func(context.Context) error { return nil })
+	if err := c.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+}
+
+func TestShutdownRunsOnce(t *testing.T) {
+	c := newTestCoordinator()
+	var calls int
+	c.Register("once", PriorityHTTP, func(context.Context) error {
+		calls++
+		return errors.New("failed")
+	})
+
+	first := c.Shutdown(context.Background())
+	second := c.Shutdown(context.Background())
+	if calls != 1 {
+		t.Errorf("hook calls = %d, want 1", calls)
+	}
+	if first == nil || first != second {
+		t.Errorf("results differ: %v, %v", first, second)
+	}
+}
+
+func TestWaitContext(t *testing.T) {
+	c := newTestCoordinator(WithSignals(syscall.SIGUSR2))
+	var calls int
+	c.Register("hook", PriorityHTTP, func(context.Context) error {
+		calls++
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := c.Wait(ctx); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	// Later calls return the same result without running hooks again.
+	if err := c.Wait(context.Background()); err != nil {
+		t.Fatalf("second Wait() error = %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("hook calls = %d, want 1", calls)
+	}
+}
+
+func TestWaitSecondSignalForcesExit(t *testing.T) {
+	exited := make(chan int, 1)
+	c := newTestCoordinator(WithSignals(syscall.SIGUSR1))
+	c.exit = func(code int) { exited <- code }
+
+	hookStarted := make(chan struct{})
+	release := make(chan struct{})
+	c.Register("slow", PriorityHTTP, func(context.Context) error {
+		close(hookStarted)
+		<-release
+		return nil
+	})
+
+	// Keep SIGUSR1 from killing the test binary if it arrives before Wait
+	// has called signal.Notify.
+	guard := make(chan os.Signal, 16)
+	signal.Notify(guard, syscall.SIGUSR1)
+	defer signal.Stop(guard)
+
+	waitErr := make(chan error, 1)
+	go func() {
+		waitErr <- c.Wait(context.Background())
+	}()
+
+	// Wait registers for the signal in its goroutine; resend until the
+	// hook starts.
+	deadline := time.After(5 * time.Second)
+	for started := false; !started; {
+		if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-hookStarted:
+			started = true
+		case <-time.After(10 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("shutdown did not start on the first signal")
+		}
+	}
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case code := <-exited:
+		if code != 1 {
+			t.Errorf("exit code = %d, want 1", code)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("second signal did not force exit")
+	}
+
+	close(release)
+	if err := <-waitErr; err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+// Package version reports which build of a service is running.
+//
+// Set the variables at build time with ldflags:
+//
+//	PKG := github.com/11SF/go-common/version
+//	go build -ldflags "\
+//		-X $(PKG).Version=$(shell git describe --tags --always) \
+//		-X $(PKG).Commit=$(shell git rev-parse HEAD) \
+//		-X $(PKG).BuildDate=$(shell date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Anything left unset falls back to the Go build info: the main module
+// version and the vcs.revision and vcs.time stamped by go build.
+//
+// The package only uses the standard library so that logger can import it;
+// the HTTP handler lives in versionhttp.
+package version
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// Set through -ldflags "-X ...".
+var (
+	Version   string
+	Commit    string
+	BuildDate string
+)
+
+const unknown = "unknown"
+
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	// Modified is set when the build info reports uncommitted changes.
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+var readBuildInfo = debug.ReadBuildInfo
+
+var buildInfo = sync.OnceValue(loadBuildInfo)
+
+func loadBuildInfo() BuildInfo {
+	var info BuildInfo
+	bi, ok := readBuildInfo()
+	if !ok {
+		return info
+	}
+	info.Version = bi.Main.Version
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Commit = setting.Value
+		case "vcs.time":
+			info.BuildDate = setting.Value
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
+
+// Info returns the ldflags values, falling back to the Go build info and
+// then to "unknown" for each field.
+func Info() BuildInfo {
+	fallback := buildInfo()
+	return BuildInfo{
+		Version:   firstNonEmpty(Version, fallback.Version),
+		Commit:    firstNonEmpty(Commit, fallback.Commit),
+		BuildDate: firstNonEmpty(BuildDate, fallback.BuildDate),
+		Modified:  Commit == "" && fallback.Modified,
+		GoVersion: runtime.Version(),
+	}
+}
+
+// String formats Info on one line, e.g. "v1.4.0 (commit 3b742d8, built
+// 2024-05-01T10:00:00Z)".
+func String() string {
+	info := Info()
+	commit := info.Commit
+	if len(commit) > 7 {
+		commit = commit[:7]
+	}
+	if info.Modified {
+		commit += "-dirty"
+	}
+	return fmt.Sprintf("%s (commit %s, built %s)", info.Version, commit, info.BuildDate)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" && value != "(devel)" {
+			return value
+		}
+	}
+	return unknown
+}
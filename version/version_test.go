@@ -0,0 +1,124 @@
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"testing"
+)
+
+// setBuildInfo replaces the build info source and resets the cache.
+func setBuildInfo(t *testing.T, bi *debug.BuildInfo) {
+	t.Helper()
+	prevRead, prevCache := readBuildInfo, buildInfo
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return bi, bi != nil
+	}
+	buildInfo = sync.OnceValue(loadBuildInfo)
+	t.Cleanup(func() {
+		readBuildInfo, buildInfo = prevRead, prevCache
+	})
+}
+
+func setLdflags(t *testing.T, version, commit, buildDate string) {
+	t.Helper()
+	prevVersion, prevCommit, prevBuildDate := Version, Commit, BuildDate
+	Version, Commit, BuildDate = version, commit, buildDate
+	t.Cleanup(func() {
+		Version, Commit, BuildDate = prevVersion, prevCommit, prevBuildDate
+	})
+}
+
+func TestInfo(t *testing.T) {
+	vcsBuild := &debug.BuildInfo{
+		Main: debug.Module{Version: "v0.9.0"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "abcdef0123456789"},
+			{Key: "vcs.time", Value: "2024-04-01T08:00:00Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		version   string
+		commit    string
+		buildDate string
+		build     *debug.BuildInfo
+		want      BuildInfo
+	}{
+		{
+			name:      "ldflags",
+			version:   "v1.2.3",
+			commit:    "0123456789abcdef",
+			buildDate: "2024-05-01T10:00:00Z",
+			build:     vcsBuild,
+			want: BuildInfo{
+				Version:   "v1.2.3",
+				Commit:    "0123456789abcdef",
+				BuildDate: "2024-05-01T10:00:00Z",
+			},
+		},
+		{
+			name:  "build info fallback",
+			build: vcsBuild,
+			want: BuildInfo{
+				Version:   "v0.9.0",
+				Commit:    "abcdef0123456789",
+				BuildDate: "2024-04-01T08:00:00Z",
+				Modified:  true,
+			},
+		},
+		{
+			name:    "partial ldflags",
+			version: "v1.2.3",
+			build:   vcsBuild,
+			want: BuildInfo{
+				Version:   "v1.2.3",
+				Commit:    "abcdef0123456789",
+				BuildDate: "2024-04-01T08:00:00Z",
+				Modified:  true,
+			},
+		},
+		{
+			name:  "devel module version",
+			build: &debug.BuildInfo{Main: debug.Module{Version: "(devel)"}},
+			want:  BuildInfo{Version: unknown, Commit: unknown, BuildDate: unknown},
+		},
+		{
+			name: "no build info",
+			want: BuildInfo{Version: unknown, Commit: unknown, BuildDate: unknown},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setLdflags(t, tt.version, tt.commit, tt.buildDate)
+			setBuildInfo(t, tt.build)
+
+			tt.want.GoVersion = runtime.Version()
+			if got := Info(); got != tt.want {
+				t.Errorf("Info() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	setBuildInfo(t, &debug.BuildInfo{
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "abcdef0123456789"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	})
+
+	setLdflags(t, "v1.2.3", "0123456789abcdef", "2024-05-01T10:00:00Z")
+	if got, want := String(), "v1.2.3 (commit 0123456, built 2024-05-01T10:00:00Z)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	setLdflags(t, "", "", "")
+	if got, want := String(), "unknown (commit abcdef0-dirty, built unknown)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,18 @@
+// Package versionhttp serves version.Info over HTTP.
+package versionhttp
+
+import (
+	"net/http"
+
+	"github.com/11SF/go-common/response"
+	"github.com/11SF/go-common/version"
+	"github.com/gin-gonic/gin"
+)
+
+// VersionHandler responds with version.Info in the standard response
+// envelope.
+func VersionHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response.NewGinResponse(c, http.StatusOK, version.Info())
+	}
+}
@@ -0,0 +1,37 @@
+package versionhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/11SF/go-common/version"
+	"github.com/gin-gonic/gin"
+)
+
+func TestVersionHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/version", VersionHandler())
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var body struct {
+		Code string            `json:"code"`
+		Data version.BuildInfo `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.Code != "00000" {
+		t.Errorf("code = %q, want %q", body.Code, "00000")
+	}
+	if want := version.Info(); body.Data != want {
+		t.Errorf("data = %+v, want %+v", body.Data, want)
+	}
+}